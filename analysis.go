@@ -0,0 +1,99 @@
+package twitterquerygo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kurrik/twittergo"
+)
+
+// TweetLanguages maps the ID of each tweet of a response to its detected language, read from metadata.iso_language_code
+// or, failing that, from lang. Tweets without either field are omitted, as are the tweets spilled to disk, see AllTweets
+func TweetLanguages(r *SearchTweetsResponse) map[uint64]string {
+	languages := make(map[uint64]string, len(r.Tweets))
+	for _, tweet := range r.Tweets {
+		if language := tweetLanguage(tweet); len(language) > 0 {
+			languages[tweetID(tweet)] = language
+		}
+	}
+	return languages
+}
+
+// SourceStats counts the tweets of a response by the name of the client app they were posted with, parsed out of the HTML link
+// of their source field with its HTML entities decoded, such as "Twitter for iPhone". Tweets without a source are omitted,
+// as are the tweets spilled to disk, see AllTweets
+func SourceStats(r *SearchTweetsResponse) map[string]int {
+	stats := make(map[string]int)
+	for _, tweet := range r.Tweets {
+		if source := tweetSource(tweet); len(source) > 0 {
+			stats[source]++
+		}
+	}
+	return stats
+}
+
+// GroupByAuthor groups the tweets of a response by the ID of their author, tweets whose author is unknown being grouped under zero.
+// The tweets spilled to disk are left out, see AllTweets
+func GroupByAuthor(r *SearchTweetsResponse) map[uint64][]twittergo.Tweet {
+	groups := make(map[uint64][]twittergo.Tweet)
+	for _, tweet := range r.Tweets {
+		authorID := tweetUserID(tweet)
+		groups[authorID] = append(groups[authorID], tweet)
+	}
+	return groups
+}
+
+// GroupByDay groups the tweets of a response by the day they were created on in the location set by SetDisplayLocation, keyed
+// by their date in the YYYY-MM-DD form, so that a tweet posted late in the evening UTC can fall on the next day in Asia.
+// Tweets whose creation time is missing or malformed are grouped under an empty key. The tweets spilled to disk are left out, see AllTweets
+func (c *SearchTwitterClient) GroupByDay(r *SearchTweetsResponse) map[string][]twittergo.Tweet {
+	groups := make(map[string][]twittergo.Tweet)
+	for _, tweet := range r.Tweets {
+		day := ""
+		if createdAt := tweetCreatedAt(tweet); !createdAt.IsZero() {
+			day = createdAt.In(c.location()).Format("2006-01-02")
+		}
+		groups[day] = append(groups[day], tweet)
+	}
+	return groups
+}
+
+// HashtagCooccurrence searches the tweets tagged with a hashtag, given with or without its #, and counts for every other hashtag
+// the number of these tweets it appears in. Hashtags are compared lower-cased and the counts are keyed by lower-cased hashtags
+// without their #, the seed hashtag excluded. Nothing is counted when entities are not included, see SetIncludeEntities.
+// Every page of the recent result type is searched, whatever the ResultType field says
+func (c *SearchTwitterClient) HashtagCooccurrence(hashtag string) (map[string]int, *SearchTweetsResponse, error) {
+
+	c.resetCancel()
+	defer c.useRecent()()
+
+	seed := strings.ToLower(strings.TrimPrefix(hashtag, "#"))
+	result, err := c.search(context.Background(), "#"+seed, c.SinceID, c.MaxID, c.newFilterState())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cooccurrences := make(map[string]int)
+	for _, tweet := range result.Tweets {
+		counted := map[string]bool{seed: true}
+		for _, other := range tweetHashtags(tweet) {
+			if !counted[other] {
+				counted[other] = true
+				cooccurrences[other]++
+			}
+		}
+	}
+
+	return cooccurrences, result, nil
+}
+
+// tweetLanguage returns the detected language of a tweet, or an empty string if it has none
+func tweetLanguage(t twittergo.Tweet) string {
+	if metadata, isMap := t["metadata"].(map[string]interface{}); isMap {
+		if language, isString := metadata["iso_language_code"].(string); isString && len(language) > 0 {
+			return language
+		}
+	}
+	language, _ := t["lang"].(string)
+	return language
+}
@@ -0,0 +1,70 @@
+package twitterquerygo
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxQueryTerms is the maximum number of terms SearchAny ORs together in one query, under the operator limit of the search endpoint
+const maxQueryTerms = 20
+
+// SearchAny searches the tweets matching any of the given terms, such as keywords, hashtags or quoted phrases, ORing them together.
+// Twitter rejects or silently truncates queries with too many operators, so the terms are split into as few sub-queries as needed for
+// each to hold at most 20 terms within the 500 characters of the q parameter, run one after the other. Their results are merged and
+// deduplicated, newest first. A sub-query that does not exhaust its results, such as on reaching the rate limit, ends the search with
+// its StopReason. The since_id and max_id of the client are used and left unchanged
+func (c *SearchTwitterClient) SearchAny(terms []string) (*SearchTweetsResponse, error) {
+	c.resetCancel()
+	result := &SearchTweetsResponse{StopReason: StopReasonExhausted}
+	filters := c.newFilterState()
+	for _, query := range splitTerms(terms, maxQueryTerms, maxQueryLength) {
+		sub, err := c.search(context.Background(), query, c.SinceID, c.MaxID, filters)
+		if err != nil {
+			return nil, err
+		}
+		result.Merge(sub)
+		if sub.StopReason != StopReasonExhausted {
+			result.StopReason = sub.StopReason
+			break
+		}
+	}
+
+	sort.SliceStable(result.Tweets, func(i, j int) bool {
+		return tweetID(result.Tweets[i]) > tweetID(result.Tweets[j])
+	})
+	if c.oldestFirst {
+		reverseTweets(result.Tweets)
+	}
+
+	return result, nil
+}
+
+// splitTerms ORs the non-blank terms together into queries of at most maxTerms terms and maxLength characters each,
+// a single term longer than that making a query of its own
+func splitTerms(terms []string, maxTerms int, maxLength int) []string {
+	var queries []string
+	var chunk []string
+	length := 0
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if len(term) == 0 {
+			continue
+		}
+		termLength := utf8.RuneCountInString(term)
+		if len(chunk) > 0 && (len(chunk) == maxTerms || length+len(" OR ")+termLength > maxLength) {
+			queries = append(queries, strings.Join(chunk, " OR "))
+			chunk, length = nil, 0
+		}
+		if len(chunk) > 0 {
+			length += len(" OR ")
+		}
+		chunk = append(chunk, term)
+		length += termLength
+	}
+	if len(chunk) > 0 {
+		queries = append(queries, strings.Join(chunk, " OR "))
+	}
+	return queries
+}
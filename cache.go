@@ -0,0 +1,60 @@
+package twitterquerygo
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultResponseCacheTTL is how long a cached search body is served for by default, one rate limit window of the search endpoint
+const defaultResponseCacheTTL = 15 * time.Minute
+
+// ResponseCache stores the bodies of successful search responses, attached with SetResponseCache, so that a request identical
+// to an earlier one returns the stored body instead of being sent, such as to spare the rate limit while iterating on a program.
+// The key is the full query URL of the request, the path of the search endpoint followed by its encoded query string, with the
+// q, count, result_type, since_id and max_id parameters among others, so that only the very same page is ever served from it.
+// An implementation must be safe for concurrent use
+type ResponseCache interface {
+	// Get returns the body stored for the URL, and false when there is none or it has expired
+	Get(url string) ([]byte, bool)
+	// Set stores the body of the URL for the given time to live
+	Set(url string, body []byte, ttl time.Duration)
+}
+
+// MemoryResponseCache is a ResponseCache keeping the bodies in memory, expired ones being dropped when looked up
+type MemoryResponseCache struct {
+	mutex   sync.Mutex
+	entries map[string]cachedResponse
+}
+
+// cachedResponse holds a cached body along with the time it expires at
+type cachedResponse struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+// NewMemoryResponseCache creates an empty MemoryResponseCache
+func NewMemoryResponseCache() *MemoryResponseCache {
+	return &MemoryResponseCache{entries: make(map[string]cachedResponse)}
+}
+
+// Get returns the body stored for the URL, and false when there is none or it has expired
+func (m *MemoryResponseCache) Get(url string) ([]byte, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	entry, found := m.entries[url]
+	if !found {
+		return nil, false
+	}
+	if !time.Now().Before(entry.expiresAt) {
+		delete(m.entries, url)
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// Set stores the body of the URL for the given time to live
+func (m *MemoryResponseCache) Set(url string, body []byte, ttl time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.entries[url] = cachedResponse{body: append([]byte(nil), body...), expiresAt: time.Now().Add(ttl)}
+}
@@ -0,0 +1,43 @@
+package twitterquerygo
+
+import (
+	"time"
+)
+
+// Clock tells the time and waits for it to pass on behalf of a client, set with SetClock so that its timings can be driven
+// deterministically, such as by a fake clock advanced by a test
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After returns a channel receiving the current time once the duration has elapsed
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock of the system, the default
+type systemClock struct{}
+
+// Now returns the time of the system
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits on the timer of the system
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// now returns the current time as told by the clock of the client
+func (c *SearchTwitterClient) now() time.Time {
+	if c.clock != nil {
+		return c.clock.Now()
+	}
+	return systemClock{}.Now()
+}
+
+// after returns a channel receiving the current time once the duration has elapsed on the clock of the client
+func (c *SearchTwitterClient) after(d time.Duration) <-chan time.Time {
+	if c.clock != nil {
+		return c.clock.After(d)
+	}
+	return systemClock{}.After(d)
+}
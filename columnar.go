@@ -0,0 +1,61 @@
+package twitterquerygo
+
+import (
+	"time"
+)
+
+// ColumnWriter receives the tweets of a response as typed columns, one call per column, all of them holding one value per tweet
+// in the same order. It lets an adapter export the tweets to a columnar format such as Parquet or Arrow without this package
+// depending on it
+type ColumnWriter interface {
+	// WriteUint64Column receives the id and author_id columns, zero standing for an unknown author
+	WriteUint64Column(name string, values []uint64) error
+	// WriteTimeColumn receives the created_at column, the zero time standing for a missing or malformed timestamp
+	WriteTimeColumn(name string, values []time.Time) error
+	// WriteStringColumn receives the text and lang columns
+	WriteStringColumn(name string, values []string) error
+	// WriteInt64Column receives the favorite_count and retweet_count columns
+	WriteInt64Column(name string, values []int64) error
+}
+
+// WriteColumnar writes the tweets of the response to a ColumnWriter, column by column in the order id, author_id, created_at,
+// text, lang, favorite_count and retweet_count, stopping at the first error. The tweets spilled to disk are written too, first
+func (r *SearchTweetsResponse) WriteColumnar(w ColumnWriter) error {
+	count := r.TotalTweets()
+	ids := make([]uint64, 0, count)
+	authorIDs := make([]uint64, 0, count)
+	createdAts := make([]time.Time, 0, count)
+	texts := make([]string, 0, count)
+	langs := make([]string, 0, count)
+	favorites := make([]int64, 0, count)
+	retweets := make([]int64, 0, count)
+	for tweet, err := range r.AllTweets() {
+		if err != nil {
+			return err
+		}
+		lang, _ := tweet["lang"].(string)
+		ids = append(ids, tweetID(tweet))
+		authorIDs = append(authorIDs, tweetUserID(tweet))
+		createdAts = append(createdAts, tweetCreatedAt(tweet))
+		texts = append(texts, TweetText(tweet))
+		langs = append(langs, lang)
+		favorites = append(favorites, tweetCount(tweet, "favorite_count"))
+		retweets = append(retweets, tweetCount(tweet, "retweet_count"))
+	}
+
+	writes := []func() error{
+		func() error { return w.WriteUint64Column("id", ids) },
+		func() error { return w.WriteUint64Column("author_id", authorIDs) },
+		func() error { return w.WriteTimeColumn("created_at", createdAts) },
+		func() error { return w.WriteStringColumn("text", texts) },
+		func() error { return w.WriteStringColumn("lang", langs) },
+		func() error { return w.WriteInt64Column("favorite_count", favorites) },
+		func() error { return w.WriteInt64Column("retweet_count", retweets) },
+	}
+	for _, write := range writes {
+		if err := write(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
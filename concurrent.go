@@ -0,0 +1,110 @@
+package twitterquerygo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kurrik/twittergo"
+)
+
+// SearchRangeConcurrent searches tweets given a search parameter 'q' within the ID range (sinceID, maxID], splitting it into
+// the given number of contiguous sub-ranges paginated concurrently, then merges the shards into a single response, newest first.
+// Both IDs are required, failing with ErrInvalidIDRange otherwise, and the client's own SinceID and MaxID are left unchanged.
+// The shards are paged by ID, so they always use the recent result type.
+//
+// Every shard draws from the same rate limit window of the search endpoint, so sharding speeds up a backfill without raising
+// how many requests fit in the window: a shard stops on its own once the limit is reached, the rate limit state of the response
+// being the most constrained one seen and its StopReason the first shard one other than StopReasonExhausted, if any.
+// Rotating tokens, see NewRotatingClient, is the way to actually raise the budget. The first shard error is returned
+func (c *SearchTwitterClient) SearchRangeConcurrent(query string, sinceID uint64, maxID uint64, shards int) (*SearchTweetsResponse, error) {
+	c.resetCancel()
+	defer c.useRecent()()
+	if sinceID == 0 || maxID == 0 || sinceID >= maxID {
+		return nil, ErrInvalidIDRange
+	}
+
+	ranges := splitIDRange(sinceID, maxID, shards)
+	if err := c.fetchAppTokens(); err != nil {
+		return nil, err
+	}
+
+	filters := c.newFilterState()
+	results := make([]*SearchTweetsResponse, len(ranges))
+	errs := make([]error, len(ranges))
+	var wg sync.WaitGroup
+	for i, idRange := range ranges {
+		wg.Add(1)
+		go func(i int, idRange [2]uint64) {
+			defer wg.Done()
+			results[i], errs[i] = c.search(context.Background(), query, idRange[0], idRange[1], filters)
+		}(i, idRange)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &SearchTweetsResponse{StopReason: StopReasonExhausted}
+	for _, shard := range results {
+		result.Merge(shard)
+		if result.StopReason == StopReasonExhausted {
+			result.StopReason = shard.StopReason
+		}
+	}
+
+	if c.oldestFirst {
+		reverseTweets(result.Tweets)
+	}
+
+	return result, nil
+}
+
+// splitIDRange splits the ID range (sinceID, maxID] into at most the given number of contiguous sub-ranges of about the same width,
+// each one holding its exclusive lower and inclusive upper bound, from the newest to the oldest one
+func splitIDRange(sinceID uint64, maxID uint64, shards int) [][2]uint64 {
+	span := maxID - sinceID
+	if shards < 1 {
+		shards = 1
+	}
+	if uint64(shards) > span {
+		shards = int(span)
+	}
+
+	width := span / uint64(shards)
+	ranges := make([][2]uint64, 0, shards)
+	upper := maxID
+	for i := shards - 1; i >= 0; i-- {
+		lower := sinceID + uint64(i)*width
+		ranges = append(ranges, [2]uint64{lower, upper})
+		upper = lower
+	}
+	return ranges
+}
+
+// fetchAppTokens fetches the bearer token of every app-auth client that has none yet, which twittergo otherwise fetches lazily
+// on the first request, so that concurrent requests do not race to do it
+func (c *SearchTwitterClient) fetchAppTokens() error {
+	if c.signer != nil {
+		return nil
+	}
+
+	clients := []*twittergo.Client{&c.TwitterClient}
+	if c.tokens != nil {
+		clients = clients[:0]
+		for _, token := range c.tokens.tokens {
+			clients = append(clients, token.client)
+		}
+	}
+
+	for _, client := range clients {
+		if client.User == nil && client.AppToken == nil {
+			if err := client.FetchAppToken(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+package twitterquerygo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// searchConfig is the serializable, query-agnostic configuration of a client. It never holds keys, secrets or tokens
+type searchConfig struct {
+	ResultType               string   `json:"result_type,omitempty"`
+	APIPath                  string   `json:"api_path,omitempty"`
+	ResultTypeFallback       []string `json:"result_type_fallback,omitempty"`
+	Language                 string   `json:"lang,omitempty"`
+	AcceptLanguages          []string `json:"accept_languages,omitempty"`
+	ExcludeSensitive         bool     `json:"exclude_sensitive,omitempty"`
+	ExcludeProtected         bool     `json:"exclude_protected,omitempty"`
+	OnlyWithMedia            bool     `json:"only_with_media,omitempty"`
+	RequireEntities          []string `json:"require_entities,omitempty"`
+	DedupByText              bool     `json:"dedup_by_text,omitempty"`
+	SkipAuthorless           bool     `json:"skip_authorless_tweets,omitempty"`
+	MinBatchSize             int      `json:"min_batch_size,omitempty"`
+	PaginationOverlap        *uint64  `json:"pagination_overlap,omitempty"`
+	MaxAuthors               int      `json:"max_authors,omitempty"`
+	MaxTweetsPerAuthor       int      `json:"max_tweets_per_author,omitempty"`
+	StopAtEngagement         int      `json:"stop_at_cumulative_engagement,omitempty"`
+	MinTextLength            int      `json:"min_text_length,omitempty"`
+	MaxTextLength            int      `json:"max_text_length,omitempty"`
+	PaginatePopular          bool     `json:"paginate_popular,omitempty"`
+	CatchUp                  bool     `json:"catch_up,omitempty"`
+	AutoTuneBatchSize        bool     `json:"auto_tune_batch_size,omitempty"`
+	MaxResponseBytes         int64    `json:"max_response_bytes,omitempty"`
+	MaxRetries               int      `json:"max_retries,omitempty"`
+	ParseTimeout             string   `json:"parse_timeout,omitempty"`
+	ResponseCacheTTL         string   `json:"response_cache_ttl,omitempty"`
+	CollectTimings           bool     `json:"collect_timings,omitempty"`
+	PredictExhaustion        bool     `json:"predict_exhaustion,omitempty"`
+	OldestFirst              bool     `json:"oldest_first,omitempty"`
+	PreserveRawJSON          bool     `json:"preserve_raw_json,omitempty"`
+	LenientIDRange           bool     `json:"lenient_id_range,omitempty"`
+	ContinueOnError          bool     `json:"continue_on_error,omitempty"`
+	WatchDedupWindow         int      `json:"watch_dedup_window,omitempty"`
+	WatchDelayed             bool     `json:"watch_delayed,omitempty"`
+	WatchBackoffMin          string   `json:"watch_backoff_min,omitempty"`
+	WatchBackoffMax          string   `json:"watch_backoff_max,omitempty"`
+	ReservoirSample          int      `json:"reservoir_sample,omitempty"`
+	BatchCallbackConcurrency int      `json:"batch_callback_concurrency,omitempty"`
+	SpillDir                 string   `json:"spill_dir,omitempty"`
+	SpillThreshold           int      `json:"spill_threshold,omitempty"`
+	FlushEvery               int      `json:"flush_every,omitempty"`
+	ExcludeSources           []string `json:"exclude_sources,omitempty"`
+	Fields                   []string `json:"fields,omitempty"`
+	TextRegex                string   `json:"text_regex,omitempty"`
+	ExcludeEntities          bool     `json:"exclude_entities,omitempty"`
+	MinAccountAge            string   `json:"min_account_age,omitempty"`
+}
+
+// MarshalConfig serializes the query-agnostic configuration of the client to JSON, such as the result type, the language and the filters.
+// Keys, secrets and tokens are never serialized, nor are the since_id and max_id or any function set on the client
+func (c *SearchTwitterClient) MarshalConfig() ([]byte, error) {
+	return json.Marshal(c.config())
+}
+
+// LoadConfig applies a configuration serialized by MarshalConfig to the client through the setters, options missing from it being
+// reset to their default value. Invalid values, such as a language code rejected by SetLanguageStrict, fail it with the client unchanged
+func (c *SearchTwitterClient) LoadConfig(data []byte) error {
+	config := searchConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	watchBackoffMin, err := parseDuration(config.WatchBackoffMin)
+	if err != nil {
+		return err
+	}
+	watchBackoffMax, err := parseDuration(config.WatchBackoffMax)
+	if err != nil {
+		return err
+	}
+	minAccountAge, err := parseDuration(config.MinAccountAge)
+	if err != nil {
+		return err
+	}
+	parseTimeout, err := parseDuration(config.ParseTimeout)
+	if err != nil {
+		return err
+	}
+	responseCacheTTL, err := parseDuration(config.ResponseCacheTTL)
+	if err != nil {
+		return err
+	}
+	var textRegex *regexp.Regexp
+	if len(config.TextRegex) > 0 {
+		if textRegex, err = regexp.Compile(config.TextRegex); err != nil {
+			return err
+		}
+	}
+	if len(config.APIPath) > 0 && !strings.HasPrefix(config.APIPath, "/") {
+		return ErrInvalidAPIPath
+	}
+
+	if len(config.Language) > 0 {
+		if err = c.SetLanguageStrict(config.Language); err != nil {
+			return err
+		}
+	} else {
+		c.Language = ""
+	}
+	if err = c.SetAPIPath(config.APIPath); err != nil {
+		return err
+	}
+	c.SetResultType(config.ResultType)
+	c.SetResultTypeFallback(config.ResultTypeFallback)
+	c.SetAcceptLanguages(config.AcceptLanguages)
+	c.SetExcludeSensitive(config.ExcludeSensitive)
+	c.SetExcludeProtected(config.ExcludeProtected)
+	// entities are included first so that the filters needing them are warned about once, by a profile excluding them
+	c.SetIncludeEntities(true)
+	c.SetOnlyWithMedia(config.OnlyWithMedia)
+	c.SetRequireEntities(config.RequireEntities)
+	c.SetIncludeEntities(!config.ExcludeEntities)
+	c.SetDedupByText(config.DedupByText)
+	c.SetSkipAuthorlessTweets(config.SkipAuthorless)
+	c.SetMinBatchSize(config.MinBatchSize)
+	if config.PaginationOverlap != nil {
+		c.SetPaginationOverlap(*config.PaginationOverlap)
+	} else {
+		c.paginationOverlap = nil
+	}
+	c.SetMaxAuthors(config.MaxAuthors)
+	c.SetMaxTweetsPerAuthor(config.MaxTweetsPerAuthor)
+	c.SetStopAtCumulativeEngagement(config.StopAtEngagement)
+	c.SetMinTextLength(config.MinTextLength)
+	c.SetMaxTextLength(config.MaxTextLength)
+	c.SetPaginatePopular(config.PaginatePopular)
+	c.SetCatchUp(config.CatchUp)
+	c.SetAutoTuneBatchSize(config.AutoTuneBatchSize)
+	c.SetMaxResponseBytes(config.MaxResponseBytes)
+	c.SetMaxRetries(config.MaxRetries)
+	c.SetParseTimeout(parseTimeout)
+	c.SetResponseCacheTTL(responseCacheTTL)
+	c.SetCollectTimings(config.CollectTimings)
+	c.SetPredictExhaustion(config.PredictExhaustion)
+	c.SetOldestFirst(config.OldestFirst)
+	c.SetPreserveRawJSON(config.PreserveRawJSON)
+	c.SetLenientIDRange(config.LenientIDRange)
+	c.SetContinueOnError(config.ContinueOnError)
+	c.SetWatchDedupWindow(config.WatchDedupWindow)
+	c.SetWatchImmediate(!config.WatchDelayed)
+	c.SetWatchBackoff(watchBackoffMin, watchBackoffMax)
+	c.SetReservoirSample(config.ReservoirSample)
+	c.SetBatchCallbackConcurrency(config.BatchCallbackConcurrency)
+	c.SetSpillToDisk(config.SpillDir, config.SpillThreshold)
+	c.SetFlushEvery(config.FlushEvery)
+	c.SetExcludeSources(config.ExcludeSources)
+	c.SetFields(config.Fields)
+	c.SetTextRegex(textRegex)
+	c.SetMinAccountAge(minAccountAge)
+
+	return nil
+}
+
+// config returns the serializable configuration of the client
+func (c *SearchTwitterClient) config() searchConfig {
+	return searchConfig{
+		ResultType:               c.resultType(),
+		APIPath:                  c.apiPath,
+		ResultTypeFallback:       c.resultTypeFallback,
+		Language:                 c.Language,
+		AcceptLanguages:          sortedKeys(c.acceptLanguages),
+		ExcludeSensitive:         c.excludeSensitive,
+		ExcludeProtected:         c.excludeProtected,
+		OnlyWithMedia:            c.onlyWithMedia,
+		RequireEntities:          sortedKeys(c.requireEntities),
+		DedupByText:              c.dedupByText,
+		SkipAuthorless:           c.skipAuthorless,
+		MinBatchSize:             c.minBatchSize,
+		PaginationOverlap:        c.paginationOverlap,
+		MaxAuthors:               c.maxAuthors,
+		MaxTweetsPerAuthor:       c.maxTweetsPerAuthor,
+		StopAtEngagement:         c.stopAtEngagement,
+		MinTextLength:            c.minTextLength,
+		MaxTextLength:            c.maxTextLength,
+		PaginatePopular:          c.paginatePopular,
+		CatchUp:                  c.catchUp,
+		AutoTuneBatchSize:        c.autoTune,
+		MaxResponseBytes:         c.maxResponseBytes,
+		MaxRetries:               c.maxRetries,
+		ParseTimeout:             formatDuration(c.parseTimeout),
+		ResponseCacheTTL:         formatDuration(c.responseCacheTTL),
+		CollectTimings:           c.collectTimings,
+		PredictExhaustion:        c.predictExhaustion,
+		OldestFirst:              c.oldestFirst,
+		PreserveRawJSON:          c.preserveRawJSON,
+		LenientIDRange:           c.lenientIDRange,
+		ContinueOnError:          c.continueOnError,
+		WatchDedupWindow:         c.watchDedupWindow,
+		WatchDelayed:             c.watchDelayed,
+		WatchBackoffMin:          formatDuration(c.watchBackoffMin),
+		WatchBackoffMax:          formatDuration(c.watchBackoffMax),
+		ReservoirSample:          c.reservoirSize,
+		BatchCallbackConcurrency: c.batchCallbackConcurrency,
+		SpillDir:                 c.spillDir,
+		SpillThreshold:           c.spillThreshold,
+		FlushEvery:               c.flushEvery,
+		ExcludeSources:           c.excludeSources,
+		Fields:                   c.fields,
+		TextRegex:                regexpString(c.textRegex),
+		ExcludeEntities:          c.excludeEntities,
+		MinAccountAge:            formatDuration(c.minAccountAge),
+	}
+}
+
+// String renders the effective configuration of the client for debugging, never including keys, secrets or tokens
+func (c *SearchTwitterClient) String() string {
+	auth := "app"
+	if c.TwitterClient.User != nil {
+		auth = "user"
+	}
+	if c.tokens != nil {
+		auth = fmt.Sprintf("rotating(%d tokens)", len(c.tokens.tokens))
+	}
+	if c.signer != nil {
+		auth = "custom"
+	}
+
+	options := []string{
+		"auth=" + auth,
+		"api_path=" + c.searchPath(),
+		"result_type=" + c.resultType(),
+		fmt.Sprintf("result_type_fallback=[%s]", strings.Join(c.resultTypeFallback, ",")),
+		"lang=" + c.Language,
+		fmt.Sprintf("since_id=%d", c.SinceID),
+		fmt.Sprintf("max_id=%d", c.MaxID),
+		fmt.Sprintf("stop_at_id=%d", c.stopAtID),
+		fmt.Sprintf("paused=%v", c.paused.Load()),
+		fmt.Sprintf("accept_languages=[%s]", strings.Join(sortedKeys(c.acceptLanguages), ",")),
+		fmt.Sprintf("exclude_sensitive=%v", c.excludeSensitive),
+		fmt.Sprintf("exclude_protected=%v", c.excludeProtected),
+		fmt.Sprintf("only_with_media=%v", c.onlyWithMedia),
+		fmt.Sprintf("require_entities=[%s]", strings.Join(sortedKeys(c.requireEntities), ",")),
+		fmt.Sprintf("dedup_by_text=%v", c.dedupByText),
+		fmt.Sprintf("skip_authorless=%v", c.skipAuthorless),
+		fmt.Sprintf("min_batch_size=%d", c.minBatchSize),
+		fmt.Sprintf("pagination_overlap=%d", c.maxIDStep()),
+		fmt.Sprintf("max_authors=%d", c.maxAuthors),
+		fmt.Sprintf("max_tweets_per_author=%d", c.maxTweetsPerAuthor),
+		fmt.Sprintf("stop_at_cumulative_engagement=%d", c.stopAtEngagement),
+		fmt.Sprintf("text_length=%d..%d", c.minTextLength, c.maxTextLength),
+		fmt.Sprintf("paginate_popular=%v", c.paginatePopular),
+		fmt.Sprintf("catch_up=%v", c.catchUp),
+		fmt.Sprintf("auto_tune_batch_size=%v", c.autoTune),
+		fmt.Sprintf("max_response_bytes=%d", c.maxResponseBytes),
+		fmt.Sprintf("parse_timeout=%v", c.parseTimeout),
+		fmt.Sprintf("max_retries=%d", c.maxRetries),
+		fmt.Sprintf("custom_backoff=%v", c.backoff != nil),
+		fmt.Sprintf("custom_clock=%v", c.clock != nil),
+		fmt.Sprintf("query_rewriter=%v", c.queryRewriter != nil),
+		fmt.Sprintf("on_progress=%v", c.onProgress != nil),
+		fmt.Sprintf("on_batch=%v", c.onBatch != nil),
+		fmt.Sprintf("metrics=%v", c.metrics.isSet()),
+		fmt.Sprintf("batch_callback_concurrency=%d", c.batchCallbackConcurrency),
+		fmt.Sprintf("on_tweet=%v", c.onTweet != nil),
+		fmt.Sprintf("tweet_transform=%v", c.tweetTransform != nil),
+		fmt.Sprintf("state_store=%v", c.stateStore != nil),
+		fmt.Sprintf("rate_limit_coordinator=%v", c.coordinator != nil),
+		fmt.Sprintf("request_id_generator=%v", c.requestIDGenerator != nil),
+		fmt.Sprintf("request_headers=[%s]", strings.Join(sortedHeaderNames(c.requestHeaders), ",")),
+		fmt.Sprintf("on_malformed_tweet=%v", c.onMalformedTweet != nil),
+		fmt.Sprintf("custom_response_parser=%v", c.responseParser != nil),
+		fmt.Sprintf("response_cache=%v", c.responseCache != nil),
+		fmt.Sprintf("response_cache_ttl=%v", c.cacheTTL()),
+		fmt.Sprintf("collect_timings=%v", c.collectTimings),
+		fmt.Sprintf("predict_exhaustion=%v", c.predictExhaustion),
+		fmt.Sprintf("oldest_first=%v", c.oldestFirst),
+		fmt.Sprintf("preserve_raw_json=%v", c.preserveRawJSON),
+		fmt.Sprintf("lenient_id_range=%v", c.lenientIDRange),
+		fmt.Sprintf("continue_on_error=%v", c.continueOnError),
+		fmt.Sprintf("watch_dedup_window=%d", c.watchDedupWindow),
+		fmt.Sprintf("watch_immediate=%v", !c.watchDelayed),
+		fmt.Sprintf("watch_backoff=%v..%v", c.watchBackoffMin, c.watchBackoffMax),
+		fmt.Sprintf("reservoir_sample=%d", c.reservoirSize),
+		fmt.Sprintf("spill_to_disk=%d", c.spillThreshold),
+		fmt.Sprintf("flush_every=%d", c.flushEvery),
+		fmt.Sprintf("exclude_sources=[%s]", strings.Join(c.excludeSources, ",")),
+		fmt.Sprintf("text_regex=%v", c.textRegex),
+		fmt.Sprintf("fields=[%s]", strings.Join(c.fields, ",")),
+		fmt.Sprintf("include_entities=%v", !c.excludeEntities),
+		fmt.Sprintf("min_account_age=%v", c.minAccountAge),
+		"display_location=" + c.location().String(),
+	}
+
+	return "SearchTwitterClient{" + strings.Join(options, ", ") + "}"
+}
+
+// formatDuration renders a positive duration as parsed back by time.ParseDuration, or an empty string when it is not set
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// regexpString returns the source of a pattern, or an empty string when it is not set
+func regexpString(re *regexp.Regexp) string {
+	if re == nil {
+		return ""
+	}
+	return re.String()
+}
+
+// parseDuration parses a duration rendered by formatDuration, an empty string standing for zero
+func parseDuration(value string) (time.Duration, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// sortedHeaderNames returns the names of the given headers in ascending order, leaving out their values that may hold secrets
+func sortedHeaderNames(headers http.Header) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedKeys returns the keys of a set in ascending order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,123 @@
+package twitterquerygo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/kurrik/twittergo"
+)
+
+// ErrUnknownAuthor is returned when a tweet lacks the author needed to build a search
+var ErrUnknownAuthor = errors.New("twitterquerygo: tweet has no author screen name")
+
+// FetchReplies searches the replies to a tweet, querying the tweets sent to its author after it and keeping those replying to it.
+// This is best-effort: the search API only covers recent tweets and only returns the replies matching the other settings of the client.
+// Every page of the recent result type is searched, whatever the ResultType field says
+func (c *SearchTwitterClient) FetchReplies(tweet twittergo.Tweet) (*SearchTweetsResponse, error) {
+
+	c.resetCancel()
+	defer c.useRecent()()
+
+	screenName := tweetScreenName(tweet)
+	if len(screenName) == 0 {
+		return nil, ErrUnknownAuthor
+	}
+
+	id := tweetID(tweet)
+	result, err := c.search(context.Background(), fmt.Sprintf("to:%s", screenName), id, 0, c.newFilterState())
+	if err != nil {
+		return nil, err
+	}
+
+	replies := make([]twittergo.Tweet, 0, len(result.Tweets))
+	for _, candidate := range result.Tweets {
+		if inReplyToID(candidate) == id {
+			replies = append(replies, candidate)
+		}
+	}
+	result.Tweets = replies
+	result.computeIDBounds()
+
+	return result, nil
+}
+
+// FetchQuotes searches the tweets quoting a tweet, querying its URL, which Twitter matches against the quote tweets linking to it,
+// since the tweet and keeping those whose quoted_status_id_str is its ID. This is best-effort: the search API only covers recent tweets,
+// may miss quotes it does not index by URL and only returns the quotes matching the other settings of the client.
+// Every page of the recent result type is searched, whatever the ResultType field says
+func (c *SearchTwitterClient) FetchQuotes(id uint64, authorScreenName string) (*SearchTweetsResponse, error) {
+
+	c.resetCancel()
+	defer c.useRecent()()
+
+	if len(authorScreenName) == 0 {
+		return nil, ErrUnknownAuthor
+	}
+
+	query := fmt.Sprintf("https://twitter.com/%s/status/%d", authorScreenName, id)
+	result, err := c.search(context.Background(), query, id, 0, c.newFilterState())
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]twittergo.Tweet, 0, len(result.Tweets))
+	for _, candidate := range result.Tweets {
+		if quotedID(candidate) == id {
+			quotes = append(quotes, candidate)
+		}
+	}
+	result.Tweets = quotes
+	result.computeIDBounds()
+
+	return result, nil
+}
+
+// GroupConversations clusters the tweets of a response into conversations by following their reply chains, keyed by the ID of the root tweet.
+// A tweet replying to a tweet outside of the response is the root of its own conversation, so one thread may be split into several groups
+func GroupConversations(r *SearchTweetsResponse) map[uint64][]twittergo.Tweet {
+
+	parents := make(map[uint64]uint64, len(r.Tweets))
+	for _, tweet := range r.Tweets {
+		parents[tweetID(tweet)] = inReplyToID(tweet)
+	}
+
+	conversations := make(map[uint64][]twittergo.Tweet)
+	for _, tweet := range r.Tweets {
+		root := tweetID(tweet)
+		for steps := 0; steps < len(parents); steps++ {
+			parent, isKnown := parents[root]
+			if !isKnown || parent == 0 {
+				break
+			}
+			if _, isInResponse := parents[parent]; !isInResponse {
+				break
+			}
+			root = parent
+		}
+		conversations[root] = append(conversations[root], tweet)
+	}
+
+	return conversations
+}
+
+// inReplyToID returns the ID of the tweet a tweet replies to, or zero if it is not a reply
+func inReplyToID(t twittergo.Tweet) uint64 {
+	return parseIDField(t, "in_reply_to_status_id_str")
+}
+
+// quotedID returns the ID of the tweet a tweet quotes, or zero if it is not a quote
+func quotedID(t twittergo.Tweet) uint64 {
+	return parseIDField(t, "quoted_status_id_str")
+}
+
+// parseIDField parses a string ID field of a tweet, returning zero when it is missing or malformed
+func parseIDField(t twittergo.Tweet, field string) uint64 {
+	idStr, _ := t[field].(string)
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,60 @@
+package twitterquerygo
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitCoordinator shares the rate limit budget of the search endpoint across the clients of one process sending their requests
+// with the same token, attached with SetRateLimitCoordinator, so that together they do not send more requests than the window allows.
+// Each search request reserves one request of the budget, which is refreshed from the rate limit headers of every response. Until
+// the first response the budget is unknown and requests are let through. It is safe for concurrent use
+type RateLimitCoordinator struct {
+	mutex     sync.Mutex
+	known     bool
+	limit     uint32
+	remaining uint32
+	reset     time.Time
+}
+
+// NewRateLimitCoordinator creates a RateLimitCoordinator whose budget is learned from the first response
+func NewRateLimitCoordinator() *RateLimitCoordinator {
+	return &RateLimitCoordinator{}
+}
+
+// Remaining returns the requests left in the current window, the time the window resets at, and false when the budget is not known yet
+func (r *RateLimitCoordinator) Remaining() (uint32, time.Time, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.remaining, r.reset, r.known
+}
+
+// acquire reserves a request of the budget, returning false along with the rate limit and reset of the window when none is left.
+// A window whose reset time has passed is deemed renewed
+func (r *RateLimitCoordinator) acquire(now time.Time) (bool, uint32, time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.known || !now.Before(r.reset) {
+		return true, r.limit, r.reset
+	}
+	if r.remaining == 0 {
+		return false, r.limit, r.reset
+	}
+	r.remaining--
+	return true, r.limit, r.reset
+}
+
+// observe refreshes the budget from the rate limit state of a response. Within the same window the lowest remaining count wins,
+// since the requests reserved by other clients may not be accounted for by Twitter yet, while a later window replaces the budget
+func (r *RateLimitCoordinator) observe(response *SearchTweetsResponse) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.known || response.RateLimitReset.After(r.reset) {
+		r.known = true
+		r.limit = response.RateLimit
+		r.remaining = response.RateLimitRemaining
+		r.reset = response.RateLimitReset
+	} else if response.RateLimitReset.Equal(r.reset) && response.RateLimitRemaining < r.remaining {
+		r.remaining = response.RateLimitRemaining
+	}
+}
@@ -0,0 +1,52 @@
+package twitterquerygo
+
+import (
+	"context"
+
+	"github.com/kurrik/twittergo"
+)
+
+// SearchCursor records where a search interrupted before it was exhausted, such as by the rate limit, left off, as set in the Cursor
+// field of its response, so that SearchFromCursor can resume it exactly, even from another process once serialized to JSON.
+// A search of the recent result type resumes from its MaxID, one of a result type paged by next_results from its NextResults
+type SearchCursor struct {
+	Query       string `json:"query"`
+	ResultType  string `json:"result_type,omitempty"`
+	SinceID     uint64 `json:"since_id,omitempty"`
+	MaxID       uint64 `json:"max_id,omitempty"`
+	NextResults string `json:"next_results,omitempty"`
+}
+
+// SearchFromCursor resumes the search the cursor was recorded for, with its result type, paging down from its max_id or following
+// its next_results cursor, the response holding the tweets fetched from there on and a cursor of its own if interrupted again.
+// The other options of the client apply, while its own SinceID, MaxID and ResultType are left unchanged
+func (c *SearchTwitterClient) SearchFromCursor(cursor SearchCursor) (*SearchTweetsResponse, error) {
+	c.resetCancel()
+	if len(cursor.ResultType) > 0 {
+		defer func(resultType string) {
+			c.ResultType = resultType
+		}(c.ResultType)
+		c.ResultType = cursor.ResultType
+	}
+
+	tweets := []twittergo.Tweet{}
+	var rawTweets map[uint64][]byte
+	result, err := c.paginateFrom(context.Background(), cursor.Query, cursor.SinceID, cursor.MaxID, cursor.NextResults, false, c.newFilterState(), func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool {
+		tweets = append(tweets, batch...)
+		rawTweets = keepRawTweets(rawTweets, batch, page.rawTweets)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Tweets = tweets
+	result.rawTweets = rawTweets
+	result.computeIDBounds()
+	if c.oldestFirst {
+		reverseTweets(result.Tweets)
+	}
+	result.attachRawTweets()
+
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package twitterquerygo
+
+import (
+	"strings"
+
+	"github.com/kurrik/twittergo"
+)
+
+// ExpandedURLs returns the expanded form of the t.co links of a tweet, as found in its entities.urls[].expanded_url fields.
+// It returns nothing when the tweet carries no entities, such as when they were not requested
+func ExpandedURLs(t twittergo.Tweet) []string {
+	var expandedURLs []string
+	for _, entity := range tweetEntities(t, "urls") {
+		if expandedURL, isString := entity["expanded_url"].(string); isString && len(expandedURL) > 0 {
+			expandedURLs = append(expandedURLs, expandedURL)
+		}
+	}
+	return expandedURLs
+}
+
+// tweetHashtags returns the lower-cased text of the hashtags of a tweet, without the #
+func tweetHashtags(t twittergo.Tweet) []string {
+	var hashtags []string
+	for _, entity := range tweetEntities(t, "hashtags") {
+		if text, isString := entity["text"].(string); isString && len(text) > 0 {
+			hashtags = append(hashtags, strings.ToLower(text))
+		}
+	}
+	return hashtags
+}
+
+// tweetHasMedia reports whether a tweet has media attachments, as listed by its extended_entities.media, or else entities.media, field
+func tweetHasMedia(t twittergo.Tweet) bool {
+	for _, field := range []string{"extended_entities", "entities"} {
+		if entities, isMap := t[field].(map[string]interface{}); isMap {
+			if media, isSlice := entities["media"].([]interface{}); isSlice && len(media) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tweetHasEntity reports whether a tweet has at least one entity of the given type, media being looked up with tweetHasMedia
+func tweetHasEntity(t twittergo.Tweet, entityType string) bool {
+	if entityType == "media" {
+		return tweetHasMedia(t)
+	}
+	return len(tweetEntities(t, entityType)) > 0
+}
+
+// tweetEntities returns the entities of the given type of a tweet, skipping any malformed entry
+func tweetEntities(t twittergo.Tweet, entityType string) []map[string]interface{} {
+	entities, isMap := t["entities"].(map[string]interface{})
+	if !isMap {
+		return nil
+	}
+	list, isSlice := entities[entityType].([]interface{})
+	if !isSlice {
+		return nil
+	}
+	result := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if entity, isMap := item.(map[string]interface{}); isMap {
+			result = append(result, entity)
+		}
+	}
+	return result
+}
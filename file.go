@@ -0,0 +1,80 @@
+package twitterquerygo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/kurrik/twittergo"
+)
+
+// SearchToFile searches tweets given a search parameter 'q' like Search does, but appends each kept tweet to the JSON Lines file
+// at the given path, created if need be, as its batch is fetched instead of holding them, newest first whatever SetOldestFirst says.
+// It returns the number of tweets written along with the response, whose Tweets field is empty and whose MinID and MaxID bound
+// the written tweets. The since_id and max_id of the client are used and left unchanged. A search or write error ends the search and
+// is returned along with the count of the tweets flushed to the file before it, the tweets buffered till then being flushed first,
+// and synced to disk when SetFlushEvery is set
+func (c *SearchTwitterClient) SearchToFile(query string, path string) (*SearchTweetsResponse, int, error) {
+	c.resetCancel()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	written, flushed := 0, 0
+	flush := func() error {
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if c.flushEvery > 0 {
+			if err := file.Sync(); err != nil {
+				return err
+			}
+		}
+		flushed = written
+		return nil
+	}
+
+	var minID, maxID uint64
+	var writeErr error
+	result, err := c.paginate(context.Background(), query, c.SinceID, c.MaxID, false, c.newFilterState(), func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool {
+		for _, tweet := range batch {
+			if writeErr = encoder.Encode(tweet); writeErr != nil {
+				return false
+			}
+			written++
+			id := tweetID(tweet)
+			if minID == 0 || id < minID {
+				minID = id
+			}
+			if id > maxID {
+				maxID = id
+			}
+			if c.flushEvery > 0 && written%c.flushEvery == 0 {
+				if writeErr = flush(); writeErr != nil {
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if writeErr == nil {
+		writeErr = flush()
+	}
+	if err = errors.Join(err, writeErr); err != nil {
+		return nil, flushed, err
+	}
+	if err = file.Close(); err != nil {
+		return nil, flushed, err
+	}
+
+	result.Tweets = []twittergo.Tweet{}
+	result.MinID = minID
+	result.MaxID = maxID
+	return result, written, nil
+}
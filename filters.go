@@ -0,0 +1,264 @@
+package twitterquerygo
+
+import (
+	"hash/fnv"
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/kurrik/twittergo"
+)
+
+// filterTweets returns the tweets kept by the client-side filters, as changed by the tweet transform and the field projection if any,
+// leaving the given slice untouched. The filters spanning the whole search record what they saw into its state. It also reports
+// whether the callback set by SetOnTweet asked for the search to stop. A tweet is only recorded into the state once kept, not when
+// dropped by the callback or the transform
+func (c *SearchTwitterClient) filterTweets(tweets []twittergo.Tweet, state *filterState) ([]twittergo.Tweet, bool) {
+	kept := make([]twittergo.Tweet, 0, len(tweets))
+	stopped := false
+	for _, tweet := range tweets {
+		if !c.keepTweet(tweet) {
+			continue
+		}
+		if !state.allows(tweet, c.maxTweetsPerAuthor) {
+			continue
+		}
+		original := tweet
+		if c.onTweet != nil {
+			keep, stop := c.onTweet(tweet)
+			stopped = stopped || stop
+			if !keep {
+				continue
+			}
+		}
+		if c.tweetTransform != nil {
+			if tweet = c.tweetTransform(tweet); tweet == nil {
+				continue
+			}
+		}
+		if !state.admit(original, c.maxTweetsPerAuthor) {
+			continue
+		}
+		if c.fields != nil {
+			tweet = projectTweet(tweet, c.fields)
+		}
+		kept = append(kept, tweet)
+	}
+	return kept, stopped
+}
+
+// warnEntityFilter logs a warning that the given client-side filter depending on entities is disabled, if entities are excluded
+func (c *SearchTwitterClient) warnEntityFilter(option string) {
+	if c.excludeEntities && c.logger != nil {
+		c.logger.Warnf("%s is disabled as long as entities are excluded, see SetIncludeEntities", option)
+	}
+}
+
+// filterState holds what the client-side filters and the stop conditions spanning a whole search saw so far, each map being nil
+// when its option is not set. A public method creates one and shares it with every pagination it runs, such as the passes of
+// SearchForward or the shards of SearchRangeConcurrent, so that it is safe for concurrent use
+type filterState struct {
+	mutex sync.Mutex
+	// seenTexts holds the hashes of the texts seen when deduplicating by text
+	seenTexts map[uint64]bool
+	// authorTweets counts the tweets kept per author when capping them
+	authorTweets map[uint64]int
+	// authors holds the distinct authors of the kept tweets when stopping at a number of them
+	authors map[uint64]bool
+	// engagement sums the favorite_count and retweet_count fields of the kept tweets
+	engagement int64
+	// collected and requests count the tweets collected and the requests sent so far, as told to the SetOnProgress function
+	collected int
+	requests  int
+}
+
+// newFilterState returns the state of the client-side filters and the stop conditions for a new search
+func (c *SearchTwitterClient) newFilterState() *filterState {
+	state := &filterState{}
+	if c.dedupByText {
+		state.seenTexts = make(map[uint64]bool)
+	}
+	if c.maxTweetsPerAuthor > 0 {
+		state.authorTweets = make(map[uint64]int)
+	}
+	if c.maxAuthors > 0 {
+		state.authors = make(map[uint64]bool)
+	}
+	return state
+}
+
+// allows reports whether a tweet passes the deduplication by text and the per author cap, without recording it
+func (s *filterState) allows(tweet twittergo.Tweet, maxTweetsPerAuthor int) bool {
+	if s.seenTexts == nil && s.authorTweets == nil {
+		return true
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, _, _, allowed := s.check(tweet, maxTweetsPerAuthor)
+	return allowed
+}
+
+// admit reports whether a tweet passes the deduplication by text and the per author cap, recording it when it does
+func (s *filterState) admit(tweet twittergo.Tweet, maxTweetsPerAuthor int) bool {
+	if s.seenTexts == nil && s.authorTweets == nil {
+		return true
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hash, hasText, authorID, allowed := s.check(tweet, maxTweetsPerAuthor)
+	if !allowed {
+		return false
+	}
+	if hasText {
+		s.seenTexts[hash] = true
+	}
+	if s.authorTweets != nil && authorID != 0 {
+		s.authorTweets[authorID]++
+	}
+	return true
+}
+
+// record adds the kept tweets of a batch to the authors and the engagement of the search, returning the number of distinct authors
+// and the engagement so far
+func (s *filterState) record(batch []twittergo.Tweet) (int, int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, tweet := range batch {
+		s.engagement += tweetCount(tweet, "favorite_count") + tweetCount(tweet, "retweet_count")
+		if authorID := tweetUserID(tweet); s.authors != nil && authorID != 0 {
+			s.authors[authorID] = true
+		}
+	}
+	return len(s.authors), s.engagement
+}
+
+// check returns the text hash and the author ID of a tweet, along with whether it passes the deduplication by text and the per author
+// cap, the mutex being held
+func (s *filterState) check(tweet twittergo.Tweet, maxTweetsPerAuthor int) (uint64, bool, uint64, bool) {
+	var hash uint64
+	hasText := false
+	if s.seenTexts != nil {
+		if hash, hasText = textHash(tweet); hasText && s.seenTexts[hash] {
+			return hash, hasText, 0, false
+		}
+	}
+	authorID := tweetUserID(tweet)
+	if s.authorTweets != nil && authorID != 0 && s.authorTweets[authorID] >= maxTweetsPerAuthor {
+		return hash, hasText, authorID, false
+	}
+	return hash, hasText, authorID, true
+}
+
+// countRequest counts a search request sent, a response served by the cache not being one
+func (s *filterState) countRequest() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests++
+}
+
+// progress adds the tweets of a batch to those collected so far, returning their number and the number of requests sent so far
+func (s *filterState) progress(tweets int) (int, int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.collected += tweets
+	return s.collected, s.requests
+}
+
+// textHash returns the FNV-1a hash of the text of a tweet as compared when deduplicating by text: stripped of its links and mentions,
+// located by the entities or else by their form, with its HTML entities decoded, lower-cased and with its whitespace collapsed.
+// It returns false when nothing is left of the text, such a tweet never being deemed a duplicate
+func textHash(tweet twittergo.Tweet) (uint64, bool) {
+	text := NormalizeText(tweet, NormalizeOptions{RemoveURLs: true, StripMentions: true, DecodeHTML: true})
+	text = linkOrMentionPattern.ReplaceAllString(text, "")
+	text = strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	if len(text) == 0 {
+		return 0, false
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(text))
+	return hash.Sum64(), true
+}
+
+// linkOrMentionPattern matches the links and the mentions left in a text whose entities are missing
+var linkOrMentionPattern = regexp.MustCompile(`https?://\S+|@\w+`)
+
+// textLength returns the length in runes of the text of a tweet as returned by TweetText, with its HTML entities decoded,
+// so that an emoji or a CJK character counts as one whatever its byte length, and &amp; as the & it stands for
+func textLength(tweet twittergo.Tweet) int {
+	return utf8.RuneCountInString(html.UnescapeString(TweetText(tweet)))
+}
+
+// projectTweet returns a copy of a tweet holding only the given fields, along with its id_str
+func projectTweet(tweet twittergo.Tweet, fields []string) twittergo.Tweet {
+	projected := make(twittergo.Tweet, len(fields)+1)
+	for _, field := range fields {
+		if value, isPresent := tweet[field]; isPresent {
+			projected[field] = value
+		}
+	}
+	if idStr, isPresent := tweet["id_str"]; isPresent {
+		projected["id_str"] = idStr
+	}
+	return projected
+}
+
+// keepTweet reports whether the tweet passes every configured client-side filter
+func (c *SearchTwitterClient) keepTweet(tweet twittergo.Tweet) bool {
+	if c.acceptLanguages != nil {
+		lang, _ := tweet["lang"].(string)
+		if !c.acceptLanguages[lang] {
+			return false
+		}
+	}
+	if c.skipAuthorless && tweetUserID(tweet) == 0 {
+		return false
+	}
+	if c.excludeSensitive {
+		if sensitive, _ := tweet["possibly_sensitive"].(bool); sensitive {
+			return false
+		}
+	}
+	if c.textRegex != nil && !c.textRegex.MatchString(TweetText(tweet)) {
+		return false
+	}
+	if c.onlyWithMedia && !c.excludeEntities && !tweetHasMedia(tweet) {
+		return false
+	}
+	if c.requireEntities != nil && !c.excludeEntities {
+		for entityType := range c.requireEntities {
+			if !tweetHasEntity(tweet, entityType) {
+				return false
+			}
+		}
+	}
+	if c.minTextLength > 0 || c.maxTextLength > 0 {
+		length := textLength(tweet)
+		if length < c.minTextLength || (c.maxTextLength > 0 && length > c.maxTextLength) {
+			return false
+		}
+	}
+	if c.excludeProtected {
+		if protected, _ := tweetUser(tweet)["protected"].(bool); protected {
+			return false
+		}
+	}
+	if c.minAccountAge > 0 {
+		if createdAt := tweetUserCreatedAt(tweet); !createdAt.IsZero() && c.now().Sub(createdAt) < c.minAccountAge {
+			return false
+		}
+	}
+	if len(c.excludeSources) > 0 {
+		source := strings.ToLower(tweetSource(tweet))
+		for _, excluded := range c.excludeSources {
+			if strings.Contains(source, excluded) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package twitterquerygo
+
+import (
+	"context"
+
+	"github.com/kurrik/twittergo"
+)
+
+// SearchForward searches every tweet given a search parameter 'q' newer than sinceID, oldest first, climbing from it in passes.
+// Twitter only ever returns results newest first, so each pass pages downwards through the tweets newer than the current since_id,
+// as Search does, then advances since_id to the newest ID it saw, the next pass fetching the tweets posted in the meantime, until a
+// pass finds no newer tweet. Unlike Search, which descends from the newest tweet towards older ones, the collection grows towards
+// the present and its MaxID is the since_id of a later catch-up. A pass that does not exhaust its range, such as on reaching the
+// rate limit, ends the search with its StopReason, the oldest tweets of that pass then possibly missing. The client's own SinceID
+// and MaxID are left unchanged. The passes climb by ID, so they always use the recent result type
+func (c *SearchTwitterClient) SearchForward(query string, sinceID uint64) (*SearchTweetsResponse, error) {
+	c.resetCancel()
+	defer c.useRecent()()
+	result := &SearchTweetsResponse{}
+	filters := c.newFilterState()
+	for {
+		pass, err := c.search(context.Background(), query, sinceID, 0, filters)
+		if err != nil {
+			return nil, err
+		}
+
+		reverseTweets(pass.Tweets)
+		result.Merge(pass)
+		result.StopReason = pass.StopReason
+		if pass.StopReason != StopReasonExhausted || pass.newestID <= sinceID {
+			return result, nil
+		}
+		sinceID = pass.newestID
+		result.newestID = sinceID
+	}
+}
+
+// catchUpNewer fetches the tweets posted while a descending search was running, newer than the newest one it saw, which the search
+// cannot reach since it only ever pages towards older tweets. It climbs from there like SearchForward does and prepends them to the
+// tweets of the result, newest first, a pass that does not exhaust its range setting its StopReason on the result
+func (c *SearchTwitterClient) catchUpNewer(query string, result *SearchTweetsResponse, filters *filterState) error {
+	var newer []twittergo.Tweet
+	for sinceID := result.newestID; ; {
+		pass, err := c.search(context.Background(), query, sinceID, 0, filters)
+		if err != nil {
+			return err
+		}
+
+		newer = append(pass.Tweets, newer...)
+		result.Errors = append(result.Errors, pass.Errors...)
+		result.requests += pass.requests
+		result.rawTweets = keepRawTweets(result.rawTweets, pass.Tweets, pass.rawTweets)
+		if pass.HasRateLimit {
+			result.HasRateLimit = true
+			result.RateLimit = pass.RateLimit
+			result.RateLimitRemaining = pass.RateLimitRemaining
+			result.RateLimitReset = pass.RateLimitReset
+		}
+
+		if pass.StopReason != StopReasonExhausted {
+			result.StopReason = pass.StopReason
+			break
+		}
+		if pass.newestID <= sinceID {
+			break
+		}
+		sinceID = pass.newestID
+		result.newestID = sinceID
+		if c.logger != nil {
+			c.logger.Debugf("caught up %d tweets newer than the first page, checking for more", len(pass.Tweets))
+		}
+	}
+
+	result.Tweets = append(newer, result.Tweets...)
+	result.computeIDBounds()
+	return nil
+}
@@ -0,0 +1,43 @@
+package twitterquerygo
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Logger defines the logging behaviour required by the client, implemented among others by *logrus.Logger, see the logruslogger
+// package. The client itself depends on no logging library
+type Logger interface {
+	Debug(args ...interface{})
+	Debugf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+var (
+	defaultLoggerMutex sync.RWMutex
+	defaultLogger      Logger
+)
+
+// SetDefaultLogger sets the logger inherited by the clients created afterwards, unless they call SetLogger, a nil one disabling logging.
+// It is safe for concurrent use
+func SetDefaultLogger(logger Logger) {
+	defaultLoggerMutex.Lock()
+	defer defaultLoggerMutex.Unlock()
+	defaultLogger = nonNilLogger(logger)
+}
+
+// nonNilLogger returns the logger, or nil when it is a nil pointer wrapped in the interface, such as a nil *logrus.Logger, which would
+// otherwise pass the nil checks of the client and panic on first use
+func nonNilLogger(logger Logger) Logger {
+	if value := reflect.ValueOf(logger); value.Kind() == reflect.Pointer && value.IsNil() {
+		return nil
+	}
+	return logger
+}
+
+// getDefaultLogger returns the logger set by SetDefaultLogger
+func getDefaultLogger() Logger {
+	defaultLoggerMutex.RLock()
+	defer defaultLoggerMutex.RUnlock()
+	return defaultLogger
+}
@@ -0,0 +1,20 @@
+// Package logruslogger adapts logrus to the Logger interface of twitterquerygo, keeping logrus out of the dependencies of the client
+// for the programs that do not log through it
+package logruslogger
+
+import (
+	"github.com/MihaiBogdanEugen/twittersearchgo"
+	"github.com/sirupsen/logrus"
+)
+
+var _ twitterquerygo.Logger = (*logrus.Logger)(nil)
+var _ twitterquerygo.Logger = (*logrus.Entry)(nil)
+
+// New returns a twitterquerygo.Logger writing to the given logrus logger. A nil logger is rejected, New returning a nil Logger that
+// disables logging rather than a nil pointer that would panic on first use; pass logrus.StandardLogger() for the standard logger
+func New(logger *logrus.Logger) twitterquerygo.Logger {
+	if logger == nil {
+		return nil
+	}
+	return logger
+}
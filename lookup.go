@@ -0,0 +1,69 @@
+package twitterquerygo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/kurrik/twittergo"
+)
+
+// HydrateTweets fetches the full tweet objects of the given IDs in batches of BatchSize, such as IDs collected earlier.
+// Deleted, protected or otherwise unavailable tweets are simply absent from the response. When the rate limit of the lookup
+// endpoint is exceeded, the tweets hydrated so far are returned with the StopReasonRateLimited stop reason
+func (c *SearchTwitterClient) HydrateTweets(ids []uint64) (*SearchTweetsResponse, error) {
+
+	result := &SearchTweetsResponse{
+		Tweets:     []twittergo.Tweet{},
+		StopReason: StopReasonExhausted,
+	}
+
+	for start := 0; start < len(ids); start += BatchSize {
+		end := start + BatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		batch := make([]string, 0, end-start)
+		for _, id := range ids[start:end] {
+			batch = append(batch, strconv.FormatUint(id, 10))
+		}
+
+		queryParams := url.Values{}
+		queryParams.Set("id", strings.Join(batch, ","))
+		response, err := c.sendRequest(context.Background(), fmt.Sprintf("/1.1/statuses/lookup.json?%v", encodeQuery(queryParams)))
+		if err != nil {
+			return nil, err
+		}
+
+		if response.HasRateLimit() {
+			result.setRateLimit(response)
+		}
+
+		timeline := twittergo.Timeline{}
+		if err = response.Parse(&timeline); err != nil {
+			if rateLimitErr, isRateLimitErr := err.(twittergo.RateLimitError); isRateLimitErr {
+				result.setRateLimit(rateLimitErr)
+				result.StopReason = StopReasonRateLimited
+				break
+			}
+			return nil, err
+		}
+		result.Tweets = append(result.Tweets, timeline...)
+
+		if c.logger != nil {
+			c.logger.Debugf("lookup of %d IDs got %d tweets, RateLimitRemaining = %d", len(batch), len(timeline), result.RateLimitRemaining)
+		}
+
+		if result.HasRateLimit && result.RateLimitRemaining == 0 && end < len(ids) {
+			result.StopReason = StopReasonRateLimited
+			break
+		}
+	}
+
+	result.computeIDBounds()
+
+	return result, nil
+}
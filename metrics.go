@@ -0,0 +1,35 @@
+package twitterquerygo
+
+// Counter is a monotonically increasing metric. It is the subset of prometheus.Counter the client needs, so that Prometheus
+// counters, or those of any other metrics library, can be handed to it without this package depending on one. It must be safe
+// for concurrent use, SearchRangeConcurrent and SearchWeighted incrementing it from several goroutines
+type Counter interface {
+	// Inc increments the counter by one
+	Inc()
+	// Add increments the counter by the given non-negative delta
+	Add(delta float64)
+}
+
+// Metrics holds the counters incremented by a client as it searches, any nil one being left out
+type Metrics struct {
+	// Requests counts the HTTP requests sent, retries included
+	Requests Counter
+	// Tweets counts the tweets received from Twitter, before any client-side filter
+	Tweets Counter
+	// RateLimitHits counts the search requests rejected because the rate limit was reached, those denied by a RateLimitCoordinator included
+	RateLimitHits Counter
+	// Retries counts the requests sent again after a network error or a 5xx status, see SetMaxRetries
+	Retries Counter
+}
+
+// isSet reports whether any counter is set
+func (m Metrics) isSet() bool {
+	return m.Requests != nil || m.Tweets != nil || m.RateLimitHits != nil || m.Retries != nil
+}
+
+// addCount increments a counter by the given delta, unless it is nil
+func addCount(counter Counter, delta int) {
+	if counter != nil {
+		counter.Add(float64(delta))
+	}
+}
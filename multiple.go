@@ -0,0 +1,119 @@
+package twitterquerygo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kurrik/twittergo"
+)
+
+// QueryError is the error a query of SearchMultiple failed with
+type QueryError struct {
+	Query string
+	Err   error
+}
+
+// Error returns the query along with the error it failed with
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("twitterquerygo: query %q failed: %v", e.Query, e.Err)
+}
+
+// Unwrap returns the error the query failed with
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError holds the errors of the queries of SearchMultiple that failed, in the order of the queries. It unwraps to them, so that
+// errors.Is and errors.As look through every one of them
+type MultiError struct {
+	Errors []*QueryError
+}
+
+// Error returns the errors of the failed queries, separated by semicolons
+func (e *MultiError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors of the failed queries
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Err returns the error the given query failed with, or nil when it did not fail
+func (e *MultiError) Err(query string) error {
+	for _, err := range e.Errors {
+		if err.Query == query {
+			return err.Err
+		}
+	}
+	return nil
+}
+
+// SearchMultiple searches tweets for several queries, one after the other, returning the results keyed by query, each query being
+// searched the way Search does, with every option of the client, such as SetResultTypeFallback, SetSpillToDisk, whose spill files are
+// to be closed by the caller, or SetOldestFirst, except that max_id is left unchanged. Partial success is the default: a failing query does not stop the others, its error being collected into a *MultiError returned along with
+// the results of the queries that succeeded, which the map then lacks. Once the rate limit is exceeded without another token to
+// rotate to, the queries left are not sent, getting empty results with the StopReasonRateLimited stop reason. A query given twice
+// is searched once. Once Cancel is called, the queries left get empty results with the StopReasonCancelled stop reason too.
+// The since_id and max_id of the client are used for every query and left unchanged
+func (c *SearchTwitterClient) SearchMultiple(queries []string) (map[string]*SearchTweetsResponse, error) {
+	c.resetCancel()
+	results := make(map[string]*SearchTweetsResponse, len(queries))
+	multiErr := &MultiError{}
+	var rateLimited *SearchTweetsResponse
+	for _, query := range queries {
+		if _, searched := results[query]; searched || multiErr.Err(query) != nil {
+			continue
+		}
+
+		if c.cancelled.Load() {
+			results[query] = &SearchTweetsResponse{
+				Tweets:      []twittergo.Tweet{},
+				StopReason:  StopReasonCancelled,
+				query:       query,
+				collectedAt: time.Now(),
+			}
+			continue
+		}
+		if rateLimited != nil {
+			results[query] = &SearchTweetsResponse{
+				Tweets:             []twittergo.Tweet{},
+				StopReason:         StopReasonRateLimited,
+				HasRateLimit:       rateLimited.HasRateLimit,
+				RateLimit:          rateLimited.RateLimit,
+				RateLimitRemaining: rateLimited.RateLimitRemaining,
+				RateLimitReset:     rateLimited.RateLimitReset,
+				query:              query,
+				collectedAt:        time.Now(),
+			}
+			continue
+		}
+
+		result, err := c.collect(query)
+		if err != nil {
+			if c.logger != nil {
+				c.logger.Warnf("query %q failed: %v", query, err)
+			}
+			multiErr.Errors = append(multiErr.Errors, &QueryError{Query: query, Err: err})
+			continue
+		}
+		results[query] = result
+		if result.StopReason == StopReasonRateLimited && (c.tokens == nil || !c.tokens.available(c.searchPath())) {
+			rateLimited = result
+		}
+	}
+
+	if len(multiErr.Errors) > 0 {
+		return results, multiErr
+	}
+	return results, nil
+}
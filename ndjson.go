@@ -0,0 +1,35 @@
+package twitterquerygo
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/kurrik/twittergo"
+)
+
+// tweetEnvelope wraps a tweet written by WriteNDJSONWithMeta along with its provenance
+type tweetEnvelope struct {
+	CollectedAt string          `json:"collected_at"`
+	Query       string          `json:"query"`
+	Tweet       twittergo.Tweet `json:"tweet"`
+}
+
+// WriteNDJSONWithMeta writes every tweet of the response, those spilled to disk included, as a line of JSON wrapping it in an envelope
+// recording its provenance for downstream auditing: {"collected_at": ..., "query": ..., "tweet": {...}}. The collected_at timestamp is
+// the time the search completed, in UTC as per RFC 3339, and the query the one the search was run with, before any rewriting
+func (r *SearchTweetsResponse) WriteNDJSONWithMeta(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	collectedAt := r.collectedAt.UTC().Format(time.RFC3339)
+	for tweet, err := range r.AllTweets() {
+		if err != nil {
+			return err
+		}
+		if err = encoder.Encode(tweetEnvelope{CollectedAt: collectedAt, Query: r.query, Tweet: tweet}); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,35 @@
+package twitterquerygo
+
+import (
+	"encoding/json"
+)
+
+// ResponseParser extracts the tweets and the pagination cursor from the body of a search response, so that endpoints wrapping
+// their results differently than the standard v1.1 envelope can be searched, such as enterprise or compatibility ones
+type ResponseParser interface {
+	// ParseSearch returns the JSON of each tweet of the page, decoded one by one afterwards, along with the next_results cursor
+	// of the page in the form of a query string, or an empty string when there is none
+	ParseSearch(body []byte) (statuses []json.RawMessage, nextResults string, err error)
+}
+
+// StandardResponseParser parses the standard v1.1 envelope, with the tweets under statuses and the cursor under
+// search_metadata.next_results
+type StandardResponseParser struct{}
+
+// ParseSearch parses the standard v1.1 envelope
+func (StandardResponseParser) ParseSearch(body []byte) ([]json.RawMessage, string, error) {
+	page := &searchPage{}
+	if err := json.Unmarshal(body, page); err != nil {
+		return nil, "", err
+	}
+	return page.Statuses, page.SearchMetadata.NextResults, nil
+}
+
+// searchPage holds a page of search results in the standard v1.1 envelope, each tweet being decoded on its own so that a malformed
+// one only loses itself
+type searchPage struct {
+	Statuses       []json.RawMessage `json:"statuses"`
+	SearchMetadata struct {
+		NextResults string `json:"next_results"`
+	} `json:"search_metadata"`
+}
@@ -0,0 +1,75 @@
+package twitterquerygo
+
+import (
+	"sync"
+
+	"github.com/kurrik/twittergo"
+)
+
+// batchPipeline runs the batch callback of a search, either synchronously or in the background with at most a given number
+// of callbacks running at once, recording the first error any of them returns
+type batchPipeline struct {
+	onBatch func(batch []twittergo.Tweet) error
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+	failure error
+}
+
+func newBatchPipeline(onBatch func(batch []twittergo.Tweet) error, concurrency int) *batchPipeline {
+	pipeline := &batchPipeline{onBatch: onBatch}
+	if concurrency > 0 {
+		pipeline.slots = make(chan struct{}, concurrency)
+	}
+	return pipeline
+}
+
+// run hands a batch to the callback, waiting for a free slot in the background mode, and returns the first error of any callback
+// run so far, the current one included in the synchronous mode
+func (p *batchPipeline) run(batch []twittergo.Tweet) error {
+	if p.slots == nil {
+		if err := p.onBatch(batch); err != nil {
+			p.fail(err)
+		}
+		return p.err()
+	}
+
+	p.slots <- struct{}{}
+	if err := p.err(); err != nil {
+		<-p.slots
+		return err
+	}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.slots
+			p.wg.Done()
+		}()
+		if err := p.onBatch(batch); err != nil {
+			p.fail(err)
+		}
+	}()
+	return nil
+}
+
+// wait waits for every callback running in the background to return, then returns the first error of any of them
+func (p *batchPipeline) wait() error {
+	p.wg.Wait()
+	return p.err()
+}
+
+// err returns the first error returned by a callback, if any
+func (p *batchPipeline) err() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.failure
+}
+
+// fail records the error returned by a callback, unless an earlier one was already recorded
+func (p *batchPipeline) fail(err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.failure == nil {
+		p.failure = err
+	}
+}
@@ -0,0 +1,29 @@
+package twitterquerygo
+
+import (
+	"time"
+)
+
+// exhaustionPredictor extrapolates when the rate limit of the search endpoint gets exhausted from how fast a search consumes it
+type exhaustionPredictor struct {
+	start     time.Time
+	remaining uint32
+}
+
+// observe records the remaining budget seen at the given time and returns when it gets exhausted at the rate consumed since the first
+// observation, false when that rate is not known yet. A budget that went up, as when the window was reset, starts the measure over
+func (p *exhaustionPredictor) observe(now time.Time, remaining uint32) (time.Time, bool) {
+	if p.start.IsZero() || remaining > p.remaining {
+		p.start = now
+		p.remaining = remaining
+		return time.Time{}, false
+	}
+
+	consumed := p.remaining - remaining
+	elapsed := now.Sub(p.start)
+	if consumed == 0 || elapsed <= 0 {
+		return time.Time{}, false
+	}
+	perRequest := elapsed / time.Duration(consumed)
+	return now.Add(perRequest * time.Duration(remaining)), true
+}
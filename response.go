@@ -0,0 +1,109 @@
+package twitterquerygo
+
+import (
+	"github.com/kurrik/twittergo"
+)
+
+// Merge appends the tweets of another response that are not already part of this one, recomputes the ID bounds
+// and keeps the more constrained of the two rate limit states, the one with the fewest remaining requests. Only the Tweets field
+// of the other response is merged, not the tweets it spilled to disk, see AllTweets
+func (r *SearchTweetsResponse) Merge(other *SearchTweetsResponse) {
+	if other == nil {
+		return
+	}
+
+	withRaw := len(other.RawTweets) > 0 && len(other.RawTweets) == len(other.Tweets) && len(r.RawTweets) == len(r.Tweets)
+	if !withRaw && len(other.Tweets) > 0 {
+		r.RawTweets = nil
+	}
+
+	seen := make(map[uint64]bool, len(r.Tweets)+len(other.Tweets))
+	for _, tweet := range r.Tweets {
+		seen[tweetID(tweet)] = true
+	}
+	for i, tweet := range other.Tweets {
+		if id := tweetID(tweet); !seen[id] {
+			seen[id] = true
+			r.Tweets = append(r.Tweets, tweet)
+			if withRaw {
+				r.RawTweets = append(r.RawTweets, other.RawTweets[i])
+			}
+		}
+	}
+	r.Errors = append(r.Errors, other.Errors...)
+	r.BatchTimings = append(r.BatchTimings, other.BatchTimings...)
+	r.BatchResultTypes = append(r.BatchResultTypes, other.BatchResultTypes...)
+	r.BatchRequestIDs = append(r.BatchRequestIDs, other.BatchRequestIDs...)
+
+	if other.HasRateLimit && (!r.HasRateLimit || other.RateLimitRemaining < r.RateLimitRemaining) {
+		r.HasRateLimit = true
+		r.RateLimit = other.RateLimit
+		r.RateLimitRemaining = other.RateLimitRemaining
+		r.RateLimitReset = other.RateLimitReset
+	}
+	if len(r.StopReason) == 0 {
+		r.StopReason = other.StopReason
+	}
+	r.PossibleGap = r.PossibleGap || other.PossibleGap
+	r.requests += other.requests
+	if len(r.query) == 0 {
+		r.query = other.query
+	}
+	if other.collectedAt.After(r.collectedAt) {
+		r.collectedAt = other.collectedAt
+	}
+
+	r.computeIDBounds()
+}
+
+// keepRawTweets records into kept the original JSON of the given tweets, taken from those of their page, if any, returning kept
+func keepRawTweets(kept map[uint64][]byte, tweets []twittergo.Tweet, rawTweets map[uint64][]byte) map[uint64][]byte {
+	if rawTweets == nil {
+		return kept
+	}
+	if kept == nil {
+		kept = make(map[uint64][]byte, len(tweets))
+	}
+	for _, tweet := range tweets {
+		id := tweetID(tweet)
+		kept[id] = rawTweets[id]
+	}
+	return kept
+}
+
+// attachRawTweets sets RawTweets from the original JSON recorded for the tweets of the response, in the same order, if any
+func (r *SearchTweetsResponse) attachRawTweets() {
+	if r.rawTweets == nil {
+		return
+	}
+	r.RawTweets = make([][]byte, len(r.Tweets))
+	for i, tweet := range r.Tweets {
+		r.RawTweets[i] = r.rawTweets[tweetID(tweet)]
+	}
+	r.rawTweets = nil
+}
+
+// setRateLimit copies the given rate limit state into the response
+func (r *SearchTweetsResponse) setRateLimit(rateLimit twittergo.RateLimitResponse) {
+	r.HasRateLimit = true
+	r.RateLimit = rateLimit.RateLimit()
+	r.RateLimitRemaining = rateLimit.RateLimitRemaining()
+	r.RateLimitReset = rateLimit.RateLimitReset()
+}
+
+// computeIDBounds sets MinID and MaxID from the tweets of the response
+func (r *SearchTweetsResponse) computeIDBounds() {
+	r.MinID = minTweetID(r.Tweets)
+	r.MaxID = maxTweetID(r.Tweets)
+}
+
+// maxTweetID returns the largest ID of the given tweets, or zero if none of them has an ID
+func maxTweetID(tweets []twittergo.Tweet) uint64 {
+	var maxID uint64
+	for _, tweet := range tweets {
+		if id := tweetID(tweet); id > maxID {
+			maxID = id
+		}
+	}
+	return maxID
+}
@@ -0,0 +1,56 @@
+package twitterquerygo
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/kurrik/twittergo"
+)
+
+// searchOrSample pages through the tweets between sinceID and maxID like search does, keeping only a reservoir sample of them
+// when SetReservoirSample is set, or else spilling them to disk when SetSpillToDisk is set
+func (c *SearchTwitterClient) searchOrSample(ctx context.Context, query string, sinceID uint64, maxID uint64, filters *filterState) (*SearchTweetsResponse, error) {
+	if c.reservoirSize <= 0 {
+		if c.spillThreshold > 0 {
+			return c.searchSpill(ctx, query, sinceID, maxID, filters)
+		}
+		return c.search(ctx, query, sinceID, maxID, filters)
+	}
+
+	source := c.sampleSource
+	if source == nil {
+		source = rand.NewSource(time.Now().UnixNano())
+	}
+	random := rand.New(source)
+
+	sample := make([]twittergo.Tweet, 0, c.reservoirSize)
+	var rawTweets map[uint64][]byte
+	var seen int64
+	result, err := c.paginate(ctx, query, sinceID, maxID, false, filters, func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool {
+		for i, tweet := range batch {
+			seen++
+			if len(sample) < c.reservoirSize {
+				sample = append(sample, tweet)
+			} else if j := random.Int63n(seen); j < int64(c.reservoirSize) {
+				if rawTweets != nil {
+					delete(rawTweets, tweetID(sample[j]))
+				}
+				sample[j] = tweet
+			} else {
+				continue
+			}
+			rawTweets = keepRawTweets(rawTweets, batch[i:i+1], page.rawTweets)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Tweets = sample
+	result.rawTweets = rawTweets
+	result.computeIDBounds()
+
+	return result, nil
+}
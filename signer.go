@@ -0,0 +1,46 @@
+package twitterquerygo
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kurrik/oauth1a"
+	"github.com/kurrik/twittergo"
+)
+
+// RequestSigner authenticates an outgoing request, typically by setting its Authorization header
+type RequestSigner interface {
+	Sign(request *http.Request) error
+}
+
+// NewClientWithSigner creates a new SearchClient whose requests are authenticated by the given signer instead of the built-in
+// app or user authentication of twittergo, such as an OAuth 2.0 PKCE flow. The request is signed once fully built, on every attempt
+func NewClientWithSigner(signer RequestSigner) *SearchTwitterClient {
+	return &SearchTwitterClient{
+		TwitterClient: *twittergo.NewClient(&oauth1a.ClientConfig{}, nil),
+		logger:        getDefaultLogger(),
+		signer:        signer,
+	}
+}
+
+// sendSigned sends a request signed by the custom signer, resolving its URL against the host of the Twitter client
+// as twittergo does
+func (c *SearchTwitterClient) sendSigned(request *http.Request) (*twittergo.APIResponse, error) {
+	if !strings.HasPrefix(request.URL.String(), "http") {
+		resolved, err := url.Parse(fmt.Sprintf("https://%v%v", c.TwitterClient.Host, request.URL.String()))
+		if err != nil {
+			return nil, err
+		}
+		request.URL = resolved
+	}
+	if err := c.signer.Sign(request); err != nil {
+		return nil, err
+	}
+	response, err := c.TwitterClient.HttpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	return (*twittergo.APIResponse)(response), nil
+}
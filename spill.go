@@ -0,0 +1,157 @@
+package twitterquerygo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"os"
+
+	"github.com/kurrik/twittergo"
+)
+
+// spillFile is the JSON Lines file the tweets of a search were spilled to, along with their number and ID bounds
+type spillFile struct {
+	path  string
+	count int
+	minID uint64
+	maxID uint64
+}
+
+// TotalTweets returns the number of tweets of the response, those spilled to disk included
+func (r *SearchTweetsResponse) TotalTweets() int {
+	if r.spill == nil {
+		return len(r.Tweets)
+	}
+	return r.spill.count + len(r.Tweets)
+}
+
+// AllTweets returns an iterator over every tweet of the response in the order they were collected: first the tweets spilled to disk,
+// read back from the spill file, then those of the Tweets field.
+// A read error is yielded once with a nil tweet and ends the iteration. It can be iterated again till Close is called
+func (r *SearchTweetsResponse) AllTweets() iter.Seq2[twittergo.Tweet, error] {
+	return func(yield func(twittergo.Tweet, error) bool) {
+		if r.spill != nil {
+			file, err := os.Open(r.spill.path)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			defer file.Close()
+
+			decoder := json.NewDecoder(bufio.NewReader(file))
+			for {
+				tweet := twittergo.Tweet{}
+				if err = decoder.Decode(&tweet); errors.Is(err, io.EOF) {
+					break
+				} else if err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(tweet, nil) {
+					return
+				}
+			}
+		}
+
+		for _, tweet := range r.Tweets {
+			if !yield(tweet, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Close removes the spill file of the response, if any, after which AllTweets only yields the tweets of the Tweets field
+func (r *SearchTweetsResponse) Close() error {
+	if r.spill == nil {
+		return nil
+	}
+	err := os.Remove(r.spill.path)
+	r.spill = nil
+	return err
+}
+
+// searchSpill pages through the tweets between sinceID and maxID like search does, appending the collected tweets to a spill file
+// whenever there are more than the spill threshold of them in memory
+func (c *SearchTwitterClient) searchSpill(ctx context.Context, query string, sinceID uint64, maxID uint64, filters *filterState) (*SearchTweetsResponse, error) {
+
+	var file *os.File
+	var writer *bufio.Writer
+	var encoder *json.Encoder
+	spill := &spillFile{}
+	var spillErr error
+
+	tweets := []twittergo.Tweet{}
+	var rawTweets map[uint64][]byte
+	result, err := c.paginate(ctx, query, sinceID, maxID, false, filters, func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool {
+		tweets = append(tweets, batch...)
+		rawTweets = keepRawTweets(rawTweets, batch, page.rawTweets)
+		if len(tweets) <= c.spillThreshold {
+			return true
+		}
+
+		if file == nil {
+			if file, spillErr = os.CreateTemp(c.spillDir, "twitterquerygo-*.jsonl"); spillErr != nil {
+				return false
+			}
+			writer = bufio.NewWriter(file)
+			encoder = json.NewEncoder(writer)
+			spill.path = file.Name()
+			if c.logger != nil {
+				c.logger.Debugf("spilling tweets to %s", spill.path)
+			}
+		}
+		for _, tweet := range tweets {
+			if spillErr = encoder.Encode(tweet); spillErr != nil {
+				return false
+			}
+			id := tweetID(tweet)
+			if spill.minID == 0 || (id > 0 && id < spill.minID) {
+				spill.minID = id
+			}
+			if id > spill.maxID {
+				spill.maxID = id
+			}
+		}
+		spill.count += len(tweets)
+		tweets = []twittergo.Tweet{}
+		rawTweets = nil
+		return true
+	})
+
+	if file != nil {
+		if flushErr := writer.Flush(); spillErr == nil {
+			spillErr = flushErr
+		}
+		if closeErr := file.Close(); spillErr == nil {
+			spillErr = closeErr
+		}
+		if err != nil || spillErr != nil {
+			os.Remove(spill.path)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if spillErr != nil {
+		return nil, spillErr
+	}
+
+	result.Tweets = tweets
+	result.rawTweets = rawTweets
+	result.computeIDBounds()
+	if file != nil {
+		result.spill = spill
+		if result.MinID == 0 || spill.minID < result.MinID {
+			result.MinID = spill.minID
+		}
+		if spill.maxID > result.MaxID {
+			result.MaxID = spill.maxID
+		}
+	}
+
+	return result, nil
+}
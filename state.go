@@ -0,0 +1,146 @@
+package twitterquerygo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StateStore persists the since_id reached by the searches of each query, such as across restarts of a polling service
+type StateStore interface {
+	// Load returns the since_id saved for the query, or zero when none was
+	Load(query string) (uint64, error)
+	// Save records the since_id reached for the query
+	Save(query string, sinceID uint64) error
+}
+
+// FileStateStore is a StateStore keeping the since_id of every query in a JSON file, rewritten atomically on each save.
+// It is safe for concurrent use within a process, not across processes sharing the file
+type FileStateStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileStateStore creates a new FileStateStore backed by the file at the given path, created on the first save
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path}
+}
+
+// Load returns the since_id saved for the query, or zero when none was or the file does not exist yet
+func (s *FileStateStore) Load(query string) (uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	return state[query], nil
+}
+
+// Save records the since_id reached for the query, keeping those of the other queries
+func (s *FileStateStore) Save(query string, sinceID uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return err
+	}
+	state[query] = sinceID
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err = temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err = temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), s.path)
+}
+
+// read returns the since_id of every query saved in the file, none when it does not exist yet
+func (s *FileStateStore) read() (map[string]uint64, error) {
+	state := make(map[string]uint64)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// SearchSince searches tweets given a search parameter 'q' newer than the since_id saved for it by the StateStore, or than the since_id
+// of the client without a store or a saved one, then saves the ID of the newest tweet seen. A search interrupted before running out of
+// results, such as a rate limited one, saves nothing, so that the next one fetches again the tweets it left uncollected.
+// A search resumed after a long downtime may not reach back to the since_id, the search index only keeping about a week of tweets:
+// PossibleGap is then set on the response, and a warning logged, when its oldest page was full yet nothing older was returned
+func (c *SearchTwitterClient) SearchSince(query string) (*SearchTweetsResponse, error) {
+
+	c.resetCancel()
+
+	sinceID, err := c.loadSinceID(query)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.search(context.Background(), query, sinceID, 0, c.newFilterState())
+	if err != nil {
+		return nil, err
+	}
+
+	if result.PossibleGap && c.logger != nil {
+		c.logger.Warnf("search for %q ran out of results before reaching since_id %d, tweets in between may be missing", query, sinceID)
+	}
+	if result.StopReason == StopReasonExhausted && result.newestID > sinceID {
+		if err = c.saveSinceID(query, result.newestID); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.oldestFirst {
+		reverseTweets(result.Tweets)
+	}
+
+	return result, nil
+}
+
+// loadSinceID returns the since_id saved by the StateStore for the query, falling back to the since_id of the client
+func (c *SearchTwitterClient) loadSinceID(query string) (uint64, error) {
+	if c.stateStore == nil {
+		return c.SinceID, nil
+	}
+	sinceID, err := c.stateStore.Load(query)
+	if err != nil {
+		return 0, err
+	}
+	if sinceID == 0 {
+		return c.SinceID, nil
+	}
+	return sinceID, nil
+}
+
+// saveSinceID saves the since_id reached for the query with the StateStore, if any
+func (c *SearchTwitterClient) saveSinceID(query string, sinceID uint64) error {
+	if c.stateStore == nil {
+		return nil
+	}
+	return c.stateStore.Save(query, sinceID)
+}
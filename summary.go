@@ -0,0 +1,103 @@
+package twitterquerygo
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/kurrik/twittergo"
+)
+
+// summaryTopHashtags is the number of hashtags listed by a SearchSummary
+const summaryTopHashtags = 10
+
+// SearchSummary holds the aggregates of the tweets matching a search, computed as they are fetched
+type SearchSummary struct {
+	// TotalTweets is the number of tweets kept by the client-side filters
+	TotalTweets int
+	// DistinctAuthors is the number of distinct known authors of the tweets
+	DistinctAuthors int
+	// TopHashtags lists the most used hashtags, lower-cased and without the #, from the most to the least used.
+	// It is empty when entities are not included, see SetIncludeEntities
+	TopHashtags []HashtagCount
+	// Earliest and Latest are the creation times of the oldest and the newest tweets, zero when no tweet has any
+	Earliest time.Time
+	Latest   time.Time
+	// TotalFavorites and TotalRetweets sum the favorite_count and retweet_count of the tweets
+	TotalFavorites int64
+	TotalRetweets  int64
+	// StopReason tells why the search stopped, as in SearchTweetsResponse
+	StopReason string
+}
+
+// HashtagCount holds the number of tweets using a hashtag
+type HashtagCount struct {
+	Hashtag string
+	Count   int
+}
+
+// SearchSummary searches tweets given a search parameter 'q' like Search does, but aggregates them as each batch is fetched instead
+// of holding them, for dashboards over large result sets. The since_id and max_id of the client are used and left unchanged
+func (c *SearchTwitterClient) SearchSummary(query string) (*SearchSummary, error) {
+
+	c.resetCancel()
+
+	summary := &SearchSummary{}
+	authors := make(map[uint64]bool)
+	hashtags := make(map[string]int)
+	result, err := c.paginate(context.Background(), query, c.SinceID, c.MaxID, false, c.newFilterState(), func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool {
+		for _, tweet := range batch {
+			summary.add(tweet, authors, hashtags, !c.excludeEntities)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary.DistinctAuthors = len(authors)
+	summary.TopHashtags = topHashtags(hashtags, summaryTopHashtags)
+	summary.StopReason = result.StopReason
+	return summary, nil
+}
+
+// add aggregates a tweet into the summary, recording its author and, when entities are included, its hashtags into the given sets
+func (s *SearchSummary) add(tweet twittergo.Tweet, authors map[uint64]bool, hashtags map[string]int, withEntities bool) {
+	s.TotalTweets++
+	if authorID := tweetUserID(tweet); authorID != 0 {
+		authors[authorID] = true
+	}
+	if withEntities {
+		for _, hashtag := range tweetHashtags(tweet) {
+			hashtags[hashtag]++
+		}
+	}
+	if createdAt := tweetCreatedAt(tweet); !createdAt.IsZero() {
+		if s.Earliest.IsZero() || createdAt.Before(s.Earliest) {
+			s.Earliest = createdAt
+		}
+		if createdAt.After(s.Latest) {
+			s.Latest = createdAt
+		}
+	}
+	s.TotalFavorites += tweetCount(tweet, "favorite_count")
+	s.TotalRetweets += tweetCount(tweet, "retweet_count")
+}
+
+// topHashtags returns at most the given number of the most used hashtags, ties being ordered alphabetically
+func topHashtags(hashtags map[string]int, limit int) []HashtagCount {
+	counts := make([]HashtagCount, 0, len(hashtags))
+	for hashtag, count := range hashtags {
+		counts = append(counts, HashtagCount{Hashtag: hashtag, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Hashtag < counts[j].Hashtag
+	})
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts
+}
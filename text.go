@@ -0,0 +1,141 @@
+package twitterquerygo
+
+import (
+	"encoding/json"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/kurrik/twittergo"
+)
+
+// TweetText returns the best available text of a tweet whatever the tweet mode it was fetched with: the full_text of an extended tweet,
+// the extended_tweet.full_text of a tweet in compatibility mode, or else the possibly truncated text. It returns an empty string when the
+// tweet has no text
+func TweetText(t twittergo.Tweet) string {
+	if fullText, isString := t["full_text"].(string); isString {
+		return fullText
+	}
+	if extended, isMap := t["extended_tweet"].(map[string]interface{}); isMap {
+		if fullText, isString := extended["full_text"].(string); isString {
+			return fullText
+		}
+	}
+	text, _ := t["text"].(string)
+	return text
+}
+
+// NormalizeOptions tells NormalizeText how to clean the text of a tweet
+type NormalizeOptions struct {
+	// ExpandURLs replaces the t.co links with the URL they point to
+	ExpandURLs bool
+	// RemoveURLs removes the t.co links, taking precedence over ExpandURLs
+	RemoveURLs bool
+	// StripMentions removes the @mentions
+	StripMentions bool
+	// StripHashtags removes the #hashtags, along with their text
+	StripHashtags bool
+	// DecodeHTML decodes the HTML entities Twitter escapes the text with, such as &amp; and &lt;
+	DecodeHTML bool
+}
+
+// textEdit replaces the runes of a tweet text between start and end
+type textEdit struct {
+	start, end  int
+	replacement string
+}
+
+// NormalizeText returns the text of a tweet as returned by TweetText, cleaned as told by the options, with its whitespace
+// collapsed to single spaces. Links and entities are located by the indices of the tweet entities, those of extended_tweet
+// for a full_text taken from it, which refer to the text as escaped by Twitter, so entities are decoded last; a tweet without entities only gets decoded and its whitespace collapsed
+func NormalizeText(t twittergo.Tweet, opts NormalizeOptions) string {
+	var edits []textEdit
+	if opts.ExpandURLs || opts.RemoveURLs {
+		for _, entityType := range []string{"urls", "media"} {
+			for _, entity := range textEntities(t, entityType) {
+				replacement, _ := entity["expanded_url"].(string)
+				if opts.RemoveURLs {
+					replacement = ""
+				} else if len(replacement) == 0 {
+					continue
+				}
+				edits = appendTextEdit(edits, entity, replacement)
+			}
+		}
+	}
+	if opts.StripMentions {
+		for _, entity := range textEntities(t, "user_mentions") {
+			edits = appendTextEdit(edits, entity, "")
+		}
+	}
+	if opts.StripHashtags {
+		for _, entity := range textEntities(t, "hashtags") {
+			edits = appendTextEdit(edits, entity, "")
+		}
+	}
+
+	text := applyTextEdits([]rune(TweetText(t)), edits)
+	if opts.DecodeHTML {
+		text = html.UnescapeString(text)
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// textEntities returns the entities of the given type of the text returned by TweetText: those of extended_tweet when the text
+// is its full_text, or else those of the tweet
+func textEntities(t twittergo.Tweet, entityType string) []map[string]interface{} {
+	if _, isString := t["full_text"].(string); !isString {
+		if extended, isMap := t["extended_tweet"].(map[string]interface{}); isMap {
+			if _, isString = extended["full_text"].(string); isString {
+				return tweetEntities(extended, entityType)
+			}
+		}
+	}
+	return tweetEntities(t, entityType)
+}
+
+// appendTextEdit appends the replacement of the given entity, skipping it when its indices are missing or malformed
+func appendTextEdit(edits []textEdit, entity map[string]interface{}, replacement string) []textEdit {
+	indices, isSlice := entity["indices"].([]interface{})
+	if !isSlice || len(indices) != 2 {
+		return edits
+	}
+	start, startOK := entityIndex(indices[0])
+	end, endOK := entityIndex(indices[1])
+	if !startOK || !endOK || start < 0 || start > end {
+		return edits
+	}
+	return append(edits, textEdit{start: start, end: end, replacement: replacement})
+}
+
+// entityIndex converts an entity index, decoded either as a float64 or as a json.Number
+func entityIndex(value interface{}) (int, bool) {
+	switch index := value.(type) {
+	case float64:
+		return int(index), true
+	case json.Number:
+		parsed, err := index.Int64()
+		return int(parsed), err == nil
+	}
+	return 0, false
+}
+
+// applyTextEdits applies the given edits to the runes of a text, skipping those out of bounds or overlapping a previous one
+func applyTextEdits(runes []rune, edits []textEdit) string {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+
+	var builder strings.Builder
+	position := 0
+	for _, edit := range edits {
+		if edit.start < position || edit.end > len(runes) {
+			continue
+		}
+		builder.WriteString(string(runes[position:edit.start]))
+		builder.WriteString(edit.replacement)
+		position = edit.end
+	}
+	builder.WriteString(string(runes[position:]))
+	return builder.String()
+}
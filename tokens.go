@@ -0,0 +1,157 @@
+package twitterquerygo
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kurrik/oauth1a"
+	"github.com/kurrik/twittergo"
+)
+
+// rateLimitWindow is how long a token is considered rate limited when its response gives no reset time in the future,
+// the length of a Twitter rate limit window
+const rateLimitWindow = 15 * time.Minute
+
+// ErrNoCredentials is returned by NewRotatingClient when no credentials are given
+var ErrNoCredentials = errors.New("twitterquerygo: no app credentials given")
+
+// AppCredentials holds the Twitter API Consumer Key and Consumer Secret of an app
+type AppCredentials struct {
+	ConsumerKey    string
+	ConsumerSecret string
+}
+
+// tokenPool round-robins requests across several app tokens, skipping the ones whose rate limit is exhausted.
+// Rate limits are tracked per endpoint path, since Twitter has one bucket per endpoint: every result type of
+// /1.1/search/tweets.json draws from the same bucket, while /1.1/statuses/lookup.json has its own
+type tokenPool struct {
+	mutex  sync.Mutex
+	tokens []*poolToken
+	next   int
+}
+
+// poolToken holds a client of the pool along with the rate limit state last observed for it on each endpoint
+type poolToken struct {
+	client *twittergo.Client
+	limits map[string]*tokenLimit
+}
+
+// tokenLimit holds the rate limit state of a token on an endpoint
+type tokenLimit struct {
+	limited bool
+	reset   time.Time
+}
+
+// NewRotatingClient creates a new SearchClient using application authentication with each of the given credentials in turn.
+// Requests are spread round-robin across the tokens and, when the rate limit of one is exhausted, sent using the next available one,
+// so that a search only stops on rate limiting once every token is exhausted. At least one credential must be given
+func NewRotatingClient(creds []AppCredentials) (*SearchTwitterClient, error) {
+	if len(creds) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	pool := &tokenPool{}
+	for _, cred := range creds {
+		pool.tokens = append(pool.tokens, &poolToken{
+			client: twittergo.NewClient(&oauth1a.ClientConfig{
+				ConsumerKey:    cred.ConsumerKey,
+				ConsumerSecret: cred.ConsumerSecret,
+			}, nil),
+			limits: make(map[string]*tokenLimit),
+		})
+	}
+
+	return &SearchTwitterClient{
+		TwitterClient: *pool.tokens[0].client,
+		tokens:        pool,
+		logger:        getDefaultLogger(),
+	}, nil
+}
+
+// acquire returns the next token whose rate limit on the endpoint is not known to be exhausted, or the one resetting first if there is none
+func (p *tokenPool) acquire(endpoint string) *poolToken {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	var earliest *poolToken
+	for i := range p.tokens {
+		index := (p.next + i) % len(p.tokens)
+		token := p.tokens[index]
+		limit := token.limit(endpoint)
+		if limit.limited && now.After(limit.reset) {
+			limit.limited = false
+		}
+		if !limit.limited {
+			p.next = index + 1
+			return token
+		}
+		if earliest == nil || limit.reset.Before(earliest.limit(endpoint).reset) {
+			earliest = token
+		}
+	}
+	return earliest
+}
+
+// observe records the rate limit state of a token on the endpoint from the response it got, a missing or past reset time
+// keeping the token limited for a whole rate limit window
+func (p *tokenPool) observe(token *poolToken, endpoint string, response *twittergo.APIResponse) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	limit := token.limit(endpoint)
+	if response.StatusCode == twittergo.STATUS_LIMIT || (response.HasRateLimit() && response.RateLimitRemaining() == 0) {
+		now := time.Now()
+		limit.limited = true
+		limit.reset = response.RateLimitReset()
+		if !limit.reset.After(now) {
+			limit.reset = now.Add(rateLimitWindow)
+		}
+	} else if response.HasRateLimit() {
+		limit.limited = false
+	}
+}
+
+// available reports whether the rate limit of any token on the endpoint is not known to be exhausted
+func (p *tokenPool) available(endpoint string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	for _, token := range p.tokens {
+		if limit := token.limit(endpoint); !limit.limited || now.After(limit.reset) {
+			return true
+		}
+	}
+	return false
+}
+
+// earliestReset returns when the first exhausted token resets on the endpoint, or the zero time when any token is available
+func (p *tokenPool) earliestReset(endpoint string) time.Time {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	var earliest time.Time
+	for _, token := range p.tokens {
+		limit := token.limit(endpoint)
+		if !limit.limited || now.After(limit.reset) {
+			return time.Time{}
+		}
+		if earliest.IsZero() || limit.reset.Before(earliest) {
+			earliest = limit.reset
+		}
+	}
+	return earliest
+}
+
+// limit returns the rate limit state of the token on the endpoint, the pool mutex being held
+func (t *poolToken) limit(endpoint string) *tokenLimit {
+	limit, isKnown := t.limits[endpoint]
+	if !isKnown {
+		limit = &tokenLimit{}
+		t.limits[endpoint] = limit
+	}
+	return limit
+}
@@ -0,0 +1,83 @@
+package twitterquerygo
+
+import (
+	"encoding/json"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kurrik/twittergo"
+)
+
+// tweetUser returns the author of a tweet, or nil when the user object is missing or null, as happens for deleted
+// or suspended accounts. Unlike twittergo.Tweet.User it never panics
+func tweetUser(t twittergo.Tweet) twittergo.User {
+	user, isMap := t["user"].(map[string]interface{})
+	if !isMap {
+		return nil
+	}
+	return twittergo.User(user)
+}
+
+// tweetUserID returns the ID of the author of a tweet, or zero when the author is unknown
+func tweetUserID(t twittergo.Tweet) uint64 {
+	idStr, _ := tweetUser(t)["id_str"].(string)
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// tweetSource returns the name of the client app a tweet was posted with, the text of the HTML link of its source field,
+// or the field itself when it holds no link
+func tweetSource(t twittergo.Tweet) string {
+	source, _ := t["source"].(string)
+	if start := strings.Index(source, ">"); start >= 0 && strings.HasPrefix(source, "<") {
+		source = source[start+1:]
+		if end := strings.Index(source, "<"); end >= 0 {
+			source = source[:end]
+		}
+	}
+	return strings.TrimSpace(html.UnescapeString(source))
+}
+
+// tweetCreatedAt returns the creation time of a tweet, or the zero time when its created_at field is missing or malformed.
+// Unlike twittergo.Tweet.CreatedAt it never panics
+func tweetCreatedAt(t twittergo.Tweet) time.Time {
+	return parseCreatedAt(t["created_at"])
+}
+
+// tweetUserCreatedAt returns the creation time of the account of the author of a tweet, or the zero time when it is unknown
+func tweetUserCreatedAt(t twittergo.Tweet) time.Time {
+	return parseCreatedAt(tweetUser(t)["created_at"])
+}
+
+// parseCreatedAt parses a created_at field of the Twitter API, returning the zero time when it is missing or malformed
+func parseCreatedAt(value interface{}) time.Time {
+	createdAt, _ := value.(string)
+	parsed, err := time.Parse(time.RubyDate, createdAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+// tweetCount returns a counter of a tweet, such as favorite_count or retweet_count, or zero when it is missing
+func tweetCount(t twittergo.Tweet, field string) int64 {
+	switch count := t[field].(type) {
+	case float64:
+		return int64(count)
+	case json.Number:
+		parsed, _ := count.Int64()
+		return parsed
+	}
+	return 0
+}
+
+// tweetScreenName returns the screen name of the author of a tweet, or an empty string when the author is unknown
+func tweetScreenName(t twittergo.Tweet) string {
+	screenName, _ := tweetUser(t)["screen_name"].(string)
+	return screenName
+}
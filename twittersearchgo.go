@@ -2,20 +2,90 @@
 package twitterquerygo
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/kurrik/oauth1a"
 	"github.com/kurrik/twittergo"
-	"github.com/sirupsen/logrus"
 )
 
 // BatchSize Query for tweets in batches of this size
 const BatchSize = 100
 
+// timestampOutOfBoundsCode is the code of the error Twitter rejects an OAuth signature with when the local clock is skewed
+const timestampOutOfBoundsCode = 135
+
+// maxErrorBodyBytes is the most of an error response body read to tell which error it holds
+const maxErrorBodyBytes = 64 << 10
+
+// maxConsecutiveFailures is the number of batches in a row a search set to continue on error skips before it stops
+const maxConsecutiveFailures = 3
+
+// searchPath is the path of the search endpoint, whose rate limit bucket is shared by every result type
+const searchPath = "/1.1/search/tweets.json"
+
+// requestIDHeader is the header carrying the ID generated for each search request, see SetRequestIDGenerator
+const requestIDHeader = "X-Request-ID"
+
+const (
+	// autoTuneMinBatchSize is the smallest batch size used when auto-tuning
+	autoTuneMinBatchSize = 10
+
+	// autoTuneStartBatchSize is the batch size of the first request when auto-tuning
+	autoTuneStartBatchSize = 25
+
+	// autoTuneFastLatency is the latency under which the auto-tuned batch size grows
+	autoTuneFastLatency = time.Second
+
+	// autoTuneSlowLatency is the latency above which the auto-tuned batch size shrinks
+	autoTuneSlowLatency = 3 * time.Second
+)
+
+// ErrResponseTooLarge is returned when a response body exceeds the limit set by SetMaxResponseBytes
+var ErrResponseTooLarge = errors.New("twitterquerygo: response body exceeds the configured maximum size")
+
+// ErrInvalidIDRange is returned when the since_id is greater than or equal to the max_id, a range no tweet can match
+var ErrInvalidIDRange = errors.New("twitterquerygo: since_id is greater than or equal to max_id")
+
+// ErrMalformedTweet is reported to the handler set by SetOnMalformedTweet for a tweet that is not an object with an ID
+var ErrMalformedTweet = errors.New("twitterquerygo: malformed tweet")
+
+// ErrInvalidLanguage is returned by SetLanguageStrict when the code is not a language code Twitter accepts, such as "en" or "zh-cn"
+var ErrInvalidLanguage = errors.New("twitterquerygo: invalid language code")
+
+// ErrParseTimeout is returned when reading and parsing a search response takes longer than the limit set by SetParseTimeout
+var ErrParseTimeout = errors.New("twitterquerygo: reading and parsing the response timed out")
+
+// ErrInvalidWatchInterval is returned by Watch when its poll interval is zero or negative and no watch backoff replaces it
+var ErrInvalidWatchInterval = errors.New("twitterquerygo: watch interval must be positive")
+
+// ErrCancelled is returned by Watch when Cancel is called while its polling is paused
+var ErrCancelled = errors.New("twitterquerygo: watch cancelled while paused")
+
+// ErrInvalidAPIPath is returned by SetAPIPath when the path does not start with a /
+var ErrInvalidAPIPath = errors.New("twitterquerygo: API path must start with /")
+
+// langOperatorPattern matches a query holding a lang: operator, negated or not
+var langOperatorPattern = regexp.MustCompile(`(?i)(^|[\s(])-?lang:`)
+
+// languageCodePattern matches an ISO 639-1 or 639-2 code, optionally followed by BCP 47 subtags
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
 // SearchTwitterClient implements a search-optimized Twitter client.
 type SearchTwitterClient struct {
 	TwitterClient twittergo.Client
@@ -23,7 +93,76 @@ type SearchTwitterClient struct {
 	MaxID         uint64
 	ResultType    string
 	Language      string
-	logger        *logrus.Logger
+	logger        Logger
+
+	maxResponseBytes         int64
+	acceptLanguages          map[string]bool
+	oldestFirst              bool
+	cancelled                atomic.Bool
+	paused                   atomic.Bool
+	clockSkew                atomic.Int64
+	lastRateLimit            atomic.Pointer[rateLimitState]
+	maxRetries               int
+	backoff                  func(attempt int) time.Duration
+	clock                    Clock
+	collectTimings           bool
+	autoTune                 bool
+	tunedBatchSize           atomic.Int32
+	excludeSensitive         bool
+	skipAuthorless           bool
+	tokens                   *tokenPool
+	minBatchSize             int
+	lenientIDRange           bool
+	watchDedupWindow         int
+	watchDelayed             bool
+	queryRewriter            func(query string, batch int) string
+	excludeSources           []string
+	excludeEntities          bool
+	onMalformedTweet         func(raw []byte, err error)
+	resultTypeFallback       []string
+	requestHeaders           http.Header
+	reservoirSize            int
+	sampleSource             rand.Source
+	signer                   RequestSigner
+	stateStore               StateStore
+	minAccountAge            time.Duration
+	tweetTransform           func(tweet twittergo.Tweet) twittergo.Tweet
+	onProgress               func(tweetsSoFar int, requestsSoFar int, rateLimitRemaining uint32)
+	spillDir                 string
+	spillThreshold           int
+	stopAtID                 uint64
+	fields                   []string
+	excludeProtected         bool
+	watchBackoffMin          time.Duration
+	watchBackoffMax          time.Duration
+	paginatePopular          bool
+	responseParser           ResponseParser
+	responseCache            ResponseCache
+	responseCacheTTL         time.Duration
+	onBatch                  func(batch []twittergo.Tweet) error
+	batchCallbackConcurrency int
+	textRegex                *regexp.Regexp
+	continueOnError          bool
+	dedupByText              bool
+	metrics                  Metrics
+	parseTimeout             time.Duration
+	minTextLength            int
+	maxTextLength            int
+	flushEvery               int
+	predictExhaustion        bool
+	preserveRawJSON          bool
+	onlyWithMedia            bool
+	requireEntities          map[string]bool
+	coordinator              *RateLimitCoordinator
+	displayLocation          *time.Location
+	onTweet                  func(tweet twittergo.Tweet) (keep bool, stop bool)
+	maxAuthors               int
+	paginationOverlap        *uint64
+	catchUp                  bool
+	maxTweetsPerAuthor       int
+	requestIDGenerator       func() string
+	stopAtEngagement         int
+	apiPath                  string
 }
 
 // SearchTweetsResponse implements the response of a search query, containing tweets and the timestamp when the rate limit resets
@@ -33,6 +172,67 @@ type SearchTweetsResponse struct {
 	RateLimit          uint32
 	RateLimitRemaining uint32
 	RateLimitReset     time.Time
+	StopReason         string
+	Errors             []error
+	RawTweets          [][]byte
+	Cursor             *SearchCursor
+	PossibleGap        bool
+	// RateLimitEfficiency is the number of tweets collected by Search per request it sent, low for a sparse query that a smaller batch
+	// size or another result type may suit better, zero when no request was sent
+	RateLimitEfficiency float64
+	BatchTimings        []time.Duration
+	BatchResultTypes    []string
+	BatchRequestIDs     []string
+	MinID               uint64
+	MaxID               uint64
+	nextResults         string
+	rateLimited         bool
+	latency             time.Duration
+	resultType          string
+	raw                 []byte
+	lastMaxID           uint64
+	newestID            uint64
+	spill               *spillFile
+	rawTweets           map[uint64][]byte
+	query               string
+	collectedAt         time.Time
+	requestedCount      int
+	requests            int
+	requestID           string
+	cached              bool
+}
+
+const (
+	// StopReasonExhausted means the search stopped because there were no more results
+	StopReasonExhausted = "exhausted"
+
+	// StopReasonRateLimited means the search stopped because the rate limit was exceeded
+	StopReasonRateLimited = "rate_limited"
+
+	// StopReasonCancelled means the search stopped because Cancel was called or its context was cancelled
+	StopReasonCancelled = "cancelled"
+
+	// StopReasonStopped means the search stopped because its consumer asked it to
+	StopReasonStopped = "stopped"
+
+	// StopReasonFailed means the search stopped on a batch error it could not skip, as recorded in Errors, see SetContinueOnError
+	StopReasonFailed = "failed"
+
+	// StopReasonReachedStopID means the search stopped because it reached the tweet ID set by SetStopAtID
+	StopReasonReachedStopID = "reached_stop_id"
+
+	// StopReasonMaxAuthors means the search stopped because it saw the number of distinct authors set by SetMaxAuthors
+	StopReasonMaxAuthors = "max_authors"
+
+	// StopReasonReachedEngagement means the search stopped because the tweets it kept reached the engagement set by
+	// SetStopAtCumulativeEngagement
+	StopReasonReachedEngagement = "reached_engagement"
+)
+
+// rateLimitState holds the rate limit state of the search endpoint last observed by a client
+type rateLimitState struct {
+	remaining uint32
+	reset     time.Time
 }
 
 // ISearchClient defines the behaviour of a search-optimized Twitter client.
@@ -46,14 +246,275 @@ type ISearchClient interface {
 	// SetResultType sets the result_type query parameter
 	SetResultType(resultType string)
 
+	// SetResultTypeFallback sets the result types Search tries in turn till one yields tweets
+	SetResultTypeFallback(resultTypes []string)
+
 	// SetLanguage sets the lang query parameter
 	SetLanguage(language string)
 
+	// SetLanguageStrict sets the lang query parameter, failing with ErrInvalidLanguage for a malformed code
+	SetLanguageStrict(code string) error
+
 	// SetLogger sets the logger
-	SetLogger(logger *logrus.Logger)
+	SetLogger(logger Logger)
+
+	// SetMaxResponseBytes sets the maximum size of a response body
+	SetMaxResponseBytes(n int64)
+
+	// SetAcceptLanguages sets the languages of the tweets kept after fetching
+	SetAcceptLanguages(langs []string)
+
+	// SetExcludeSources sets the client apps whose tweets are dropped after fetching
+	SetExcludeSources(sources []string)
+
+	// SetIncludeEntities sets whether tweets are fetched along with their entities
+	SetIncludeEntities(includeEntities bool)
+
+	// SetOnMalformedTweet sets the handler of the tweets dropped from a page for being malformed
+	SetOnMalformedTweet(handler func(raw []byte, err error))
+
+	// SetRequestHeaders sets the extra headers sent with every request
+	SetRequestHeaders(headers http.Header)
+
+	// SetReservoirSample sets the size of the random sample of tweets Search returns instead of every tweet
+	SetReservoirSample(n int)
+
+	// SetSampleSource sets the source of randomness of the reservoir sampling
+	SetSampleSource(source rand.Source)
+
+	// SetStateStore sets the store persisting the since_id reached by SearchSince and Watch for each query
+	SetStateStore(store StateStore)
+
+	// SetMinAccountAge sets the minimum age of the account of the authors whose tweets are kept after fetching
+	SetMinAccountAge(d time.Duration)
+
+	// SetTweetTransform sets the function enriching each tweet kept after fetching
+	SetTweetTransform(transform func(tweet twittergo.Tweet) twittergo.Tweet)
+
+	// SetOnProgress sets the function told the progress of a search after each batch
+	SetOnProgress(onProgress func(tweetsSoFar int, requestsSoFar int, rateLimitRemaining uint32))
+
+	// SetSpillToDisk sets the number of tweets Search holds in memory before spilling them to a file
+	SetSpillToDisk(path string, threshold int)
+
+	// SetStopAtID sets the ID of the tweet at which pagination halts, excluded along with every older one
+	SetStopAtID(id uint64)
+
+	// SetFields sets the only fields kept on the tweets after fetching
+	SetFields(fields []string)
+
+	// SetExcludeProtected sets whether tweets from protected accounts are dropped after fetching
+	SetExcludeProtected(excludeProtected bool)
+
+	// SetWatchBackoff sets how the poll interval of Watch grows during quiet periods
+	SetWatchBackoff(min time.Duration, max time.Duration)
+
+	// SetPaginatePopular sets whether searches of the mixed and popular result types go past the first page
+	SetPaginatePopular(paginatePopular bool)
+
+	// SetResponseParser sets the parser extracting the tweets and the cursor from search responses
+	SetResponseParser(parser ResponseParser)
+
+	// SetResponseCache sets the cache serving identical search requests without sending them
+	SetResponseCache(cache ResponseCache)
+
+	// SetResponseCacheTTL sets how long the bodies stored in the response cache are served for
+	SetResponseCacheTTL(ttl time.Duration)
+
+	// SetOnBatch sets the callback handed the tweets of each batch of a search
+	SetOnBatch(onBatch func(batch []twittergo.Tweet) error)
+
+	// SetBatchCallbackConcurrency sets how many batch callbacks may run in the background while the next batches are fetched
+	SetBatchCallbackConcurrency(n int)
+
+	// SetTextRegex sets the pattern the text of the tweets kept after fetching must match
+	SetTextRegex(re *regexp.Regexp)
+
+	// SetContinueOnError sets whether a batch failing with an error is skipped instead of failing the search
+	SetContinueOnError(continueOnError bool)
+
+	// SetDedupByText sets whether tweets whose text was already seen by the search are dropped after fetching
+	SetDedupByText(dedupByText bool)
+
+	// SetMetrics sets the counters incremented as the client searches
+	SetMetrics(metrics Metrics)
+
+	// SetParseTimeout sets how long reading and parsing a search response may take
+	SetParseTimeout(d time.Duration)
+
+	// SetMinTextLength sets the minimum length in runes of the text of the tweets kept after fetching
+	SetMinTextLength(n int)
+
+	// SetMaxTextLength sets the maximum length in runes of the text of the tweets kept after fetching
+	SetMaxTextLength(n int)
+
+	// SetFlushEvery sets after how many tweets SearchToFile syncs its file to disk
+	SetFlushEvery(n int)
+
+	// SetPredictExhaustion sets whether the time the rate limit gets exhausted at is predicted and logged after each batch
+	SetPredictExhaustion(predictExhaustion bool)
+
+	// SetPreserveRawJSON sets whether the original JSON of each tweet is kept in the RawTweets field of the response
+	SetPreserveRawJSON(preserveRawJSON bool)
+
+	// SetOnlyWithMedia sets whether only the tweets with media attachments are kept after fetching
+	SetOnlyWithMedia(onlyWithMedia bool)
+
+	// SetRequireEntities sets the entity types every tweet kept after fetching must have
+	SetRequireEntities(types []string)
+
+	// SetRateLimitCoordinator sets the coordinator sharing the rate limit budget with other clients
+	SetRateLimitCoordinator(coordinator *RateLimitCoordinator)
+
+	// SetDisplayLocation sets the location GroupByDay and SearchTyped render timestamps in
+	SetDisplayLocation(loc *time.Location)
+
+	// SetOnTweet sets the function deciding for each tweet whether it is kept and whether the search stops after its batch
+	SetOnTweet(onTweet func(tweet twittergo.Tweet) (keep bool, stop bool))
+
+	// SetMaxAuthors sets the number of distinct authors after which the search stops
+	SetMaxAuthors(n int)
+
+	// SetPaginationOverlap sets how much is subtracted from the smallest ID of a page for the max_id of the next one
+	SetPaginationOverlap(n uint64)
+
+	// SetCatchUp sets whether Search fetches the tweets posted while it was paging, newer than its first page
+	SetCatchUp(catchUp bool)
+
+	// SetMaxTweetsPerAuthor sets how many tweets of a single author a search keeps at most
+	SetMaxTweetsPerAuthor(n int)
+
+	// SetRequestIDGenerator sets the function generating the ID sent along with each search request for correlation
+	SetRequestIDGenerator(generator func() string)
+
+	// SetStopAtCumulativeEngagement sets the engagement of the kept tweets after which the search stops
+	SetStopAtCumulativeEngagement(n int)
+
+	// SetAPIPath sets the path of the search endpoint requests are sent to
+	SetAPIPath(path string) error
+
+	// SetOldestFirst sets whether the returned tweets are ordered from oldest to newest
+	SetOldestFirst(oldestFirst bool)
+
+	// Cancel stops the running search at the next batch boundary
+	Cancel()
+
+	// NextSafeCall returns when the next search can be sent without exceeding the rate limit
+	NextSafeCall() time.Time
+
+	// Pause halts the polling of the running Watch loops till Resume is called
+	Pause()
+
+	// Resume resumes the polling of the Watch loops halted by Pause
+	Resume()
+
+	// SetMaxRetries sets how many times a failed request is retried
+	SetMaxRetries(maxRetries int)
+
+	// SetBackoffFunc sets the function computing the delay before each retry
+	SetBackoffFunc(backoff func(attempt int) time.Duration)
+
+	// SetCollectTimings sets whether the duration of each request is recorded
+	SetCollectTimings(collectTimings bool)
+
+	// SetClock sets the clock the client tells the time with and waits on
+	SetClock(clock Clock)
+
+	// SetAutoTuneBatchSize sets whether the batch size adapts to the response latency
+	SetAutoTuneBatchSize(autoTune bool)
+
+	// SetExcludeSensitive sets whether tweets flagged as possibly sensitive are dropped
+	SetExcludeSensitive(excludeSensitive bool)
+
+	// SetSkipAuthorlessTweets sets whether tweets without an author are dropped
+	SetSkipAuthorlessTweets(skipAuthorless bool)
+
+	// SetMinBatchSize sets the batch size under which pagination stops
+	SetMinBatchSize(n int)
+
+	// SetLenientIDRange sets whether an inverted since_id and max_id range is only logged
+	SetLenientIDRange(lenient bool)
+
+	// SetWatchDedupWindow sets how many recently delivered tweet IDs Watch remembers
+	SetWatchDedupWindow(n int)
+
+	// SetWatchImmediate sets whether Watch polls right away rather than after the first interval
+	SetWatchImmediate(immediate bool)
+
+	// SetQueryRewriter sets the function rewriting the query before each request
+	SetQueryRewriter(rewriter func(query string, batch int) string)
 
 	// Search searches tweets given a search parameter 'q' till either there are no more results or the rate limit is exceeded
 	Search(query string) (*SearchTweetsResponse, error)
+
+	// SearchWindow searches a single batch of tweets older than or equal to maxID, returning the max_id of the following batch
+	SearchWindow(query string, maxID uint64) (*SearchTweetsResponse, uint64, error)
+
+	// FetchReplies searches the replies to a tweet
+	FetchReplies(tweet twittergo.Tweet) (*SearchTweetsResponse, error)
+
+	// FetchQuotes searches the tweets quoting a tweet
+	FetchQuotes(id uint64, authorScreenName string) (*SearchTweetsResponse, error)
+
+	// Watch polls for new tweets at the given interval till the context is cancelled
+	Watch(ctx context.Context, query string, interval time.Duration, onTweet func(twittergo.Tweet)) error
+
+	// SearchSeq returns an iterator over the tweets matching a search parameter 'q'
+	SearchSeq(ctx context.Context, query string) iter.Seq2[twittergo.Tweet, error]
+
+	// HydrateTweets fetches the full tweet objects of the given IDs
+	HydrateTweets(ids []uint64) (*SearchTweetsResponse, error)
+
+	// SearchRangeConcurrent searches an ID range split into shards paginated concurrently
+	SearchRangeConcurrent(query string, sinceID uint64, maxID uint64, shards int) (*SearchTweetsResponse, error)
+
+	// SearchForward searches every tweet newer than sinceID, oldest first, advancing since_id until no newer tweet is left
+	SearchForward(query string, sinceID uint64) (*SearchTweetsResponse, error)
+
+	// SearchTyped searches tweets, returning them converted to the typed Tweet
+	SearchTyped(query string) ([]Tweet, *SearchTweetsResponse, error)
+
+	// SearchToFile appends the tweets matching a search to a JSON Lines file as they are fetched
+	SearchToFile(query string, path string) (*SearchTweetsResponse, int, error)
+
+	// GroupByDay groups the tweets of a response by the day they were created on
+	GroupByDay(r *SearchTweetsResponse) map[string][]twittergo.Tweet
+
+	// SearchFromCursor resumes a search interrupted before it was exhausted from its cursor
+	SearchFromCursor(cursor SearchCursor) (*SearchTweetsResponse, error)
+
+	// SearchAny searches the tweets matching any of the terms, split into as many sub-queries as the operator limit requires
+	SearchAny(terms []string) (*SearchTweetsResponse, error)
+
+	// SearchWeighted searches tweets for several queries, sharing the rate limit by weight
+	SearchWeighted(queries map[string]int) (map[string]*SearchTweetsResponse, error)
+
+	// SearchMultiple searches tweets for several queries one after the other, a failing query not stopping the others
+	SearchMultiple(queries []string) (map[string]*SearchTweetsResponse, error)
+
+	// HashtagCooccurrence counts the hashtags appearing along with a hashtag
+	HashtagCooccurrence(hashtag string) (map[string]int, *SearchTweetsResponse, error)
+
+	// SearchSummary aggregates the tweets matching a search instead of returning them
+	SearchSummary(query string) (*SearchSummary, error)
+
+	// SearchRaw hands the undecoded body of each page of a search to a callback
+	SearchRaw(ctx context.Context, query string, onPage func(raw []byte) error) error
+
+	// SearchSince searches tweets newer than the since_id saved for the query by the StateStore
+	SearchSince(query string) (*SearchTweetsResponse, error)
+
+	// Validate checks the query and the credentials without collecting anything
+	Validate(query string) error
+
+	// EffectiveQuery returns the q parameter a search for the base query sends
+	EffectiveQuery(baseQuery string) string
+
+	// MarshalConfig serializes the non-secret configuration to JSON
+	MarshalConfig() ([]byte, error)
+
+	// LoadConfig applies a configuration serialized by MarshalConfig
+	LoadConfig(data []byte) error
 }
 
 // NewClientUsingAppAuth creates a new SearchClient using application authentication, with a rate limited to 450 requests per 15 minutes
@@ -63,6 +524,7 @@ func NewClientUsingAppAuth(consumerKey string, consumerSecret string) *SearchTwi
 			ConsumerKey:    consumerKey,
 			ConsumerSecret: consumerSecret,
 		}, nil),
+		logger: getDefaultLogger(),
 	}
 }
 
@@ -73,6 +535,7 @@ func NewClientUsingUserAuth(consumerKey string, consumerSecret string, accessTok
 			ConsumerKey:    consumerKey,
 			ConsumerSecret: consumerSecret,
 		}, oauth1a.NewAuthorizedConfig(accessToken, accessTokenSecret)),
+		logger: getDefaultLogger(),
 	}
 }
 
@@ -82,8 +545,8 @@ func (c *SearchTwitterClient) SetSinceID(sinceID uint64) {
 }
 
 // SetLogger sets the logger
-func (c *SearchTwitterClient) SetLogger(logger *logrus.Logger) {
-	c.logger = logger
+func (c *SearchTwitterClient) SetLogger(logger Logger) {
+	c.logger = nonNilLogger(logger)
 }
 
 // SetMaxID sets the max_id query parameter
@@ -100,6 +563,15 @@ func (c *SearchTwitterClient) SetResultType(resultType string) {
 	}
 }
 
+// SetResultTypeFallback sets the result types Search tries in turn till one yields tweets
+func (c *SearchTwitterClient) SetResultTypeFallback(resultTypes []string) {
+	if len(resultTypes) == 0 {
+		c.resultTypeFallback = nil
+		return
+	}
+	c.resultTypeFallback = append([]string(nil), resultTypes...)
+}
+
 // SetLanguage sets the lang query parameter
 func (c *SearchTwitterClient) SetLanguage(language string) {
 	if len(language) > 0 {
@@ -109,159 +581,1418 @@ func (c *SearchTwitterClient) SetLanguage(language string) {
 	}
 }
 
-// Search searches tweets given a search parameter 'q' till either there are no more results or the rate limit is exceeded
-func (c *SearchTwitterClient) Search(query string) (*SearchTweetsResponse, error) {
-
-	queryParams := url.Values{}
-	queryParams.Set("count", strconv.Itoa(BatchSize))
-	if len(c.Language) > 0 {
-		queryParams.Set("lang", c.Language)
+// SetLanguageStrict sets the lang query parameter, failing with ErrInvalidLanguage for a malformed code
+func (c *SearchTwitterClient) SetLanguageStrict(code string) error {
+	normalized := strings.ToLower(strings.TrimSpace(code))
+	if !languageCodePattern.MatchString(normalized) {
+		return ErrInvalidLanguage
 	}
-	if c.MaxID > 0 {
-		queryParams.Set("max_id", strconv.FormatUint(c.MaxID, 10))
+	c.Language = normalized
+	return nil
+}
+
+// SetMaxResponseBytes sets the maximum size of a response body
+func (c *SearchTwitterClient) SetMaxResponseBytes(n int64) {
+	c.maxResponseBytes = n
+}
+
+// SetAcceptLanguages sets the languages of the tweets kept after fetching
+func (c *SearchTwitterClient) SetAcceptLanguages(langs []string) {
+	if len(langs) == 0 {
+		c.acceptLanguages = nil
+		return
 	}
-	queryParams.Set("q", query)
-	queryParams.Set("result_type", c.ResultType)
-	if c.SinceID > 0 {
-		queryParams.Set("since_id", strconv.FormatUint(c.SinceID, 10))
+	c.acceptLanguages = make(map[string]bool, len(langs))
+	for _, lang := range langs {
+		c.acceptLanguages[lang] = true
 	}
-	queryURL := fmt.Sprintf("/1.1/search/tweets.json?%v", queryParams.Encode())
+}
 
-	request, err := http.NewRequest("GET", queryURL, nil)
-	if err != nil {
-		return nil, err
+// SetExcludeSources sets the client apps whose tweets are dropped after fetching
+func (c *SearchTwitterClient) SetExcludeSources(sources []string) {
+	c.excludeSources = nil
+	for _, source := range sources {
+		if len(source) > 0 {
+			c.excludeSources = append(c.excludeSources, strings.ToLower(source))
+		}
 	}
+}
 
-	response, err := c.TwitterClient.SendRequest(request)
-	if err != nil {
-		return nil, err
+// SetIncludeEntities sets whether tweets are fetched along with their entities
+func (c *SearchTwitterClient) SetIncludeEntities(includeEntities bool) {
+	c.excludeEntities = !includeEntities
+	if c.onlyWithMedia {
+		c.warnEntityFilter("SetOnlyWithMedia")
 	}
-
-	result := &SearchTweetsResponse{}
-	if response.HasRateLimit() {
-		result.HasRateLimit = true
-		result.RateLimit = response.RateLimit()
-		result.RateLimitRemaining = response.RateLimitRemaining()
-		result.RateLimitReset = response.RateLimitReset()
+	if c.requireEntities != nil {
+		c.warnEntityFilter("SetRequireEntities")
 	}
+}
 
-	searchResults := &twittergo.SearchResults{}
-	if err = response.Parse(searchResults); err != nil {
-		if rateLimitErr, isRateLimitErr := err.(twittergo.RateLimitError); isRateLimitErr {
-			result.HasRateLimit = true
-			result.RateLimit = rateLimitErr.RateLimit()
-			result.RateLimitRemaining = rateLimitErr.RateLimitRemaining()
-			result.RateLimitReset = rateLimitErr.RateLimitReset()
-		} else {
-			return nil, err
+// SetOnMalformedTweet sets the handler of the tweets dropped from a page for being malformed
+func (c *SearchTwitterClient) SetOnMalformedTweet(handler func(raw []byte, err error)) {
+	c.onMalformedTweet = handler
+}
+
+// SetRequestHeaders sets the extra headers sent with every request
+func (c *SearchTwitterClient) SetRequestHeaders(headers http.Header) {
+	if headers == nil {
+		c.requestHeaders = nil
+		return
+	}
+	c.requestHeaders = make(http.Header, len(headers))
+	for name, values := range headers {
+		for _, value := range values {
+			c.requestHeaders.Add(name, value)
 		}
 	}
+	c.requestHeaders.Del("Authorization")
+	c.requestHeaders.Del("X-OAuth-Timestamp")
+}
 
-	if searchResults.Statuses() == nil || len(searchResults.Statuses()) == 0 {
-		return result, nil
-	}
+// SetReservoirSample sets the size of the random sample of tweets Search returns instead of every tweet
+func (c *SearchTwitterClient) SetReservoirSample(n int) {
+	c.reservoirSize = n
+}
 
-	if c.logger != nil {
-		c.logger.Debugf("response #1 got %d tweets, HasRateLimit = %v, RateLimit = %d, RateLimitRemaining = %d, RateLimitReset = %v", len(searchResults.Statuses()), response.HasRateLimit(), response.RateLimit(), response.RateLimitRemaining(), response.RateLimitReset())
-	}
+// SetSampleSource sets the source of randomness of the reservoir sampling
+func (c *SearchTwitterClient) SetSampleSource(source rand.Source) {
+	c.sampleSource = source
+}
+
+// SetStateStore sets the store persisting the since_id reached by SearchSince and Watch for each query
+func (c *SearchTwitterClient) SetStateStore(store StateStore) {
+	c.stateStore = store
+}
 
-	result.Tweets = searchResults.Statuses()
+// SetMinAccountAge sets the minimum age of the account of the authors whose tweets are kept after fetching
+func (c *SearchTwitterClient) SetMinAccountAge(d time.Duration) {
+	c.minAccountAge = d
+}
 
-	var minID uint64 = 18446744073709551615
-	for _, tweet := range searchResults.Statuses() {
-		if tweet.Id() < minID {
-			minID = tweet.Id()
-		}
+// SetTweetTransform sets the function enriching each tweet kept after fetching
+func (c *SearchTwitterClient) SetTweetTransform(transform func(tweet twittergo.Tweet) twittergo.Tweet) {
+	c.tweetTransform = transform
+}
+
+// SetOnProgress sets the function told the progress of a search after each batch
+func (c *SearchTwitterClient) SetOnProgress(onProgress func(tweetsSoFar int, requestsSoFar int, rateLimitRemaining uint32)) {
+	c.onProgress = onProgress
+}
+
+// SetSpillToDisk sets the number of tweets Search holds in memory before spilling them to a file
+func (c *SearchTwitterClient) SetSpillToDisk(path string, threshold int) {
+	c.spillDir = path
+	c.spillThreshold = threshold
+}
+
+// SetStopAtID sets the ID of the tweet at which pagination halts, excluded along with every older one
+func (c *SearchTwitterClient) SetStopAtID(id uint64) {
+	c.stopAtID = id
+}
+
+// SetFields sets the only fields kept on the tweets after fetching
+func (c *SearchTwitterClient) SetFields(fields []string) {
+	if len(fields) == 0 {
+		c.fields = nil
+		return
 	}
+	c.fields = append([]string(nil), fields...)
+}
 
-	counter := 1
+// SetExcludeProtected sets whether tweets from protected accounts are dropped after fetching
+func (c *SearchTwitterClient) SetExcludeProtected(excludeProtected bool) {
+	c.excludeProtected = excludeProtected
+}
 
-	for {
-		c.MaxID = minID - 1
-		nextResponse, err := c.searchForMore(query)
-		if err != nil {
-			return nil, err
-		}
+// SetWatchBackoff sets how the poll interval of Watch grows during quiet periods
+func (c *SearchTwitterClient) SetWatchBackoff(min time.Duration, max time.Duration) {
+	c.watchBackoffMin = min
+	c.watchBackoffMax = max
+}
 
-		result.Tweets = append(result.Tweets, nextResponse.Tweets...)
-		result.HasRateLimit = nextResponse.HasRateLimit
-		result.RateLimit = nextResponse.RateLimit
-		result.RateLimitRemaining = nextResponse.RateLimitRemaining
-		result.RateLimitReset = nextResponse.RateLimitReset
+// SetPaginatePopular sets whether searches of the mixed and popular result types go past the first page
+func (c *SearchTwitterClient) SetPaginatePopular(paginatePopular bool) {
+	c.paginatePopular = paginatePopular
+}
 
-		counter++
-		if c.logger != nil {
-			c.logger.Debugf("response #%d got %d tweets, HasRateLimit = %v, RateLimit = %d, RateLimitRemaining = %d, RateLimitReset = %v", counter, len(nextResponse.Tweets), nextResponse.HasRateLimit, nextResponse.RateLimit, nextResponse.RateLimitRemaining, nextResponse.RateLimitReset)
-		}
+// SetResponseParser sets the parser extracting the tweets and the cursor from search responses
+func (c *SearchTwitterClient) SetResponseParser(parser ResponseParser) {
+	c.responseParser = parser
+}
 
-		if result.RateLimitRemaining == 0 || len(nextResponse.Tweets) == 0 {
-			if c.logger != nil {
-				c.logger.Debug("will stop")
-			}
-			break
-		}
+// SetResponseCache sets the cache serving identical search requests without sending them
+func (c *SearchTwitterClient) SetResponseCache(cache ResponseCache) {
+	c.responseCache = cache
+}
 
-		for _, tweet := range nextResponse.Tweets {
-			if tweet.Id() < minID {
-				minID = tweet.Id()
-			}
-		}
+// SetResponseCacheTTL sets how long the bodies stored in the response cache are served for
+func (c *SearchTwitterClient) SetResponseCacheTTL(ttl time.Duration) {
+	c.responseCacheTTL = ttl
+}
+
+// cacheTTL returns the time to live of the bodies stored in the response cache
+func (c *SearchTwitterClient) cacheTTL() time.Duration {
+	if c.responseCacheTTL <= 0 {
+		return defaultResponseCacheTTL
 	}
+	return c.responseCacheTTL
+}
 
-	return result, nil
+// SetOnBatch sets the callback handed the tweets of each batch of a search
+func (c *SearchTwitterClient) SetOnBatch(onBatch func(batch []twittergo.Tweet) error) {
+	c.onBatch = onBatch
 }
 
-func (c *SearchTwitterClient) searchForMore(query string) (*SearchTweetsResponse, error) {
+// SetBatchCallbackConcurrency sets how many batch callbacks may run in the background while the next batches are fetched
+func (c *SearchTwitterClient) SetBatchCallbackConcurrency(n int) {
+	c.batchCallbackConcurrency = n
+}
 
-	queryParams := url.Values{}
-	queryParams.Set("count", strconv.Itoa(BatchSize))
-	queryParams.Set("q", query)
-	if len(c.Language) > 0 {
-		queryParams.Set("lang", c.Language)
+// SetTextRegex sets the pattern the text of the tweets kept after fetching must match
+func (c *SearchTwitterClient) SetTextRegex(re *regexp.Regexp) {
+	c.textRegex = re
+}
+
+// SetContinueOnError sets whether a batch failing with an error is skipped instead of failing the search
+func (c *SearchTwitterClient) SetContinueOnError(continueOnError bool) {
+	c.continueOnError = continueOnError
+}
+
+// SetDedupByText sets whether tweets whose text was already seen by the search are dropped after fetching
+func (c *SearchTwitterClient) SetDedupByText(dedupByText bool) {
+	c.dedupByText = dedupByText
+}
+
+// SetMetrics sets the counters incremented as the client searches
+func (c *SearchTwitterClient) SetMetrics(metrics Metrics) {
+	c.metrics = metrics
+}
+
+// SetParseTimeout sets how long reading and parsing a search response may take
+func (c *SearchTwitterClient) SetParseTimeout(d time.Duration) {
+	c.parseTimeout = d
+}
+
+// SetMinTextLength sets the minimum length in runes of the text of the tweets kept after fetching
+func (c *SearchTwitterClient) SetMinTextLength(n int) {
+	c.minTextLength = n
+}
+
+// SetMaxTextLength sets the maximum length in runes of the text of the tweets kept after fetching
+func (c *SearchTwitterClient) SetMaxTextLength(n int) {
+	c.maxTextLength = n
+}
+
+// SetFlushEvery sets after how many tweets SearchToFile syncs its file to disk
+func (c *SearchTwitterClient) SetFlushEvery(n int) {
+	c.flushEvery = n
+}
+
+// SetPredictExhaustion sets whether the time the rate limit gets exhausted at is predicted and logged after each batch
+func (c *SearchTwitterClient) SetPredictExhaustion(predictExhaustion bool) {
+	c.predictExhaustion = predictExhaustion
+}
+
+// SetPreserveRawJSON sets whether the original JSON of each tweet is kept in the RawTweets field of the response
+func (c *SearchTwitterClient) SetPreserveRawJSON(preserveRawJSON bool) {
+	c.preserveRawJSON = preserveRawJSON
+}
+
+// SetOnlyWithMedia sets whether only the tweets with media attachments are kept after fetching
+func (c *SearchTwitterClient) SetOnlyWithMedia(onlyWithMedia bool) {
+	c.onlyWithMedia = onlyWithMedia
+	if onlyWithMedia {
+		c.warnEntityFilter("SetOnlyWithMedia")
 	}
-	queryParams.Set("max_id", strconv.FormatUint(c.MaxID, 10))
-	queryParams.Set("result_type", c.ResultType)
-	if c.SinceID > 0 {
-		queryParams.Set("since_id", strconv.FormatUint(c.SinceID, 10))
+}
+
+// SetRequireEntities sets the entity types every tweet kept after fetching must have
+func (c *SearchTwitterClient) SetRequireEntities(types []string) {
+	if len(types) == 0 {
+		c.requireEntities = nil
+		return
 	}
-	queryURL := fmt.Sprintf("/1.1/search/tweets.json?%v", queryParams.Encode())
+	c.requireEntities = make(map[string]bool, len(types))
+	for _, entityType := range types {
+		c.requireEntities[entityType] = true
+	}
+	c.warnEntityFilter("SetRequireEntities")
+}
 
-	request, err := http.NewRequest("GET", queryURL, nil)
-	if err != nil {
-		return nil, err
+// SetRateLimitCoordinator sets the coordinator sharing the rate limit budget with other clients
+func (c *SearchTwitterClient) SetRateLimitCoordinator(coordinator *RateLimitCoordinator) {
+	c.coordinator = coordinator
+}
+
+// SetDisplayLocation sets the location GroupByDay and SearchTyped render timestamps in
+func (c *SearchTwitterClient) SetDisplayLocation(loc *time.Location) {
+	c.displayLocation = loc
+}
+
+// location returns the location set by SetDisplayLocation, UTC by default
+func (c *SearchTwitterClient) location() *time.Location {
+	if c.displayLocation == nil {
+		return time.UTC
 	}
+	return c.displayLocation
+}
 
-	response, err := c.TwitterClient.SendRequest(request)
-	if err != nil {
-		return nil, err
+// SetOnTweet sets the function deciding for each tweet whether it is kept and whether the search stops after its batch
+func (c *SearchTwitterClient) SetOnTweet(onTweet func(tweet twittergo.Tweet) (keep bool, stop bool)) {
+	c.onTweet = onTweet
+}
+
+// SetMaxAuthors sets the number of distinct authors after which the search stops
+func (c *SearchTwitterClient) SetMaxAuthors(n int) {
+	c.maxAuthors = n
+}
+
+// SetPaginationOverlap sets how much is subtracted from the smallest ID of a page for the max_id of the next one
+func (c *SearchTwitterClient) SetPaginationOverlap(n uint64) {
+	c.paginationOverlap = &n
+}
+
+// SetCatchUp sets whether Search fetches the tweets posted while it was paging, newer than its first page
+func (c *SearchTwitterClient) SetCatchUp(catchUp bool) {
+	c.catchUp = catchUp
+}
+
+// SetMaxTweetsPerAuthor sets how many tweets of a single author a search keeps at most
+func (c *SearchTwitterClient) SetMaxTweetsPerAuthor(n int) {
+	c.maxTweetsPerAuthor = n
+}
+
+// SetRequestIDGenerator sets the function generating the ID sent along with each search request for correlation
+func (c *SearchTwitterClient) SetRequestIDGenerator(generator func() string) {
+	c.requestIDGenerator = generator
+}
+
+// SetStopAtCumulativeEngagement sets the engagement of the kept tweets after which the search stops
+func (c *SearchTwitterClient) SetStopAtCumulativeEngagement(n int) {
+	c.stopAtEngagement = n
+}
+
+// SetAPIPath sets the path of the search endpoint requests are sent to
+func (c *SearchTwitterClient) SetAPIPath(path string) error {
+	if len(path) > 0 && !strings.HasPrefix(path, "/") {
+		return ErrInvalidAPIPath
 	}
+	c.apiPath = path
+	return nil
+}
 
-	result := &SearchTweetsResponse{
-		Tweets: []twittergo.Tweet{},
+// searchPath returns the path of the search endpoint set by SetAPIPath, the standard one by default
+func (c *SearchTwitterClient) searchPath() string {
+	if len(c.apiPath) == 0 {
+		return searchPath
 	}
+	return c.apiPath
+}
 
-	if response.HasRateLimit() {
-		result.HasRateLimit = true
-		result.RateLimit = response.RateLimit()
-		result.RateLimitRemaining = response.RateLimitRemaining()
-		result.RateLimitReset = response.RateLimitReset()
+// SetOldestFirst sets whether the returned tweets are ordered from oldest to newest
+func (c *SearchTwitterClient) SetOldestFirst(oldestFirst bool) {
+	c.oldestFirst = oldestFirst
+}
+
+// Cancel stops the search currently running on this client at the next batch boundary, the search returning the tweets collected
+// so far with the StopReasonCancelled stop reason. A method running several searches, such as SearchMultiple or SearchRangeConcurrent,
+// stops all of them. It may be called from any goroutine and has no effect on searches started afterwards
+func (c *SearchTwitterClient) Cancel() {
+	c.cancelled.Store(true)
+}
+
+// useRecent switches the client to the recent result type for a method whose paging by ID requires it, the mixed and popular
+// ones returning a single page, returning the function restoring the previous result type
+func (c *SearchTwitterClient) useRecent() func() {
+	resultType := c.ResultType
+	c.ResultType = "recent"
+	return func() {
+		c.ResultType = resultType
 	}
+}
 
-	searchResults := &twittergo.SearchResults{}
-	if err = response.Parse(searchResults); err != nil {
-		if rateLimitErr, isRateLimitErr := err.(twittergo.RateLimitError); isRateLimitErr {
-			result.HasRateLimit = true
-			result.RateLimit = rateLimitErr.RateLimit()
-			result.RateLimitRemaining = rateLimitErr.RateLimitRemaining()
-			result.RateLimitReset = rateLimitErr.RateLimitReset()
-		} else {
-			return nil, err
+// resetCancel clears a Cancel left over from an earlier search, called once by every public method starting a search
+func (c *SearchTwitterClient) resetCancel() {
+	c.cancelled.Store(false)
+}
+
+// NextSafeCall returns when the next search can be sent without exceeding the rate limit, as last observed on the search endpoint:
+// the reset time of the rate limit when no request remains in the window, or else the current time, such as before any search.
+// When rotating across several tokens, it is the first reset time of the tokens once all of them are exhausted
+func (c *SearchTwitterClient) NextSafeCall() time.Time {
+	now := time.Now()
+	if c.tokens != nil {
+		if reset := c.tokens.earliestReset(c.searchPath()); reset.After(now) {
+			return reset
 		}
+		return now
 	}
-
-	if searchResults != nil && searchResults.Statuses() != nil && len(searchResults.Statuses()) > 0 {
-		result.Tweets = searchResults.Statuses()
+	if state := c.lastRateLimit.Load(); state != nil && state.remaining == 0 && state.reset.After(now) {
+		return state.reset
 	}
+	if c.coordinator != nil {
+		if remaining, reset, known := c.coordinator.Remaining(); known && remaining == 0 && reset.After(now) {
+			return reset
+		}
+	}
+	return now
+}
+
+// recordRateLimit records the rate limit state of a search response as the last one observed by the client
+func (c *SearchTwitterClient) recordRateLimit(response *SearchTweetsResponse) {
+	c.lastRateLimit.Store(&rateLimitState{
+		remaining: response.RateLimitRemaining,
+		reset:     response.RateLimitReset,
+	})
+	if c.coordinator != nil {
+		c.coordinator.observe(response)
+	}
+}
+
+// Pause halts the polling of the Watch loops running on this client without ending them: ticks occurring while paused issue no request,
+// the since_id reached being kept. A poll already in progress completes. It may be called from any goroutine
+func (c *SearchTwitterClient) Pause() {
+	c.paused.Store(true)
+}
+
+// Resume resumes the polling of the Watch loops halted by Pause at their next tick, from the since_id reached before pausing
+func (c *SearchTwitterClient) Resume() {
+	c.paused.Store(false)
+}
+
+// SetMaxRetries sets how many times a failed request is retried
+func (c *SearchTwitterClient) SetMaxRetries(maxRetries int) {
+	c.maxRetries = maxRetries
+}
+
+// SetBackoffFunc sets the function computing the delay before each retry
+func (c *SearchTwitterClient) SetBackoffFunc(backoff func(attempt int) time.Duration) {
+	c.backoff = backoff
+}
+
+// SetCollectTimings sets whether the duration of each request is recorded
+func (c *SearchTwitterClient) SetCollectTimings(collectTimings bool) {
+	c.collectTimings = collectTimings
+}
+
+// SetClock sets the clock the client tells the time with and waits on
+func (c *SearchTwitterClient) SetClock(clock Clock) {
+	c.clock = clock
+}
+
+// SetAutoTuneBatchSize sets whether the batch size adapts to the response latency
+func (c *SearchTwitterClient) SetAutoTuneBatchSize(autoTune bool) {
+	c.autoTune = autoTune
+	c.tunedBatchSize.Store(autoTuneStartBatchSize)
+}
+
+// SetExcludeSensitive sets whether tweets flagged as possibly sensitive are dropped
+func (c *SearchTwitterClient) SetExcludeSensitive(excludeSensitive bool) {
+	c.excludeSensitive = excludeSensitive
+}
+
+// SetSkipAuthorlessTweets sets whether tweets without an author are dropped
+func (c *SearchTwitterClient) SetSkipAuthorlessTweets(skipAuthorless bool) {
+	c.skipAuthorless = skipAuthorless
+}
+
+// SetMinBatchSize sets the batch size under which pagination stops
+func (c *SearchTwitterClient) SetMinBatchSize(n int) {
+	c.minBatchSize = n
+}
+
+// SetLenientIDRange sets whether an inverted since_id and max_id range is only logged
+func (c *SearchTwitterClient) SetLenientIDRange(lenient bool) {
+	c.lenientIDRange = lenient
+}
+
+// SetWatchDedupWindow sets how many recently delivered tweet IDs Watch remembers
+func (c *SearchTwitterClient) SetWatchDedupWindow(n int) {
+	c.watchDedupWindow = n
+}
+
+// SetWatchImmediate sets whether Watch polls right away rather than after the first interval
+func (c *SearchTwitterClient) SetWatchImmediate(immediate bool) {
+	c.watchDelayed = !immediate
+}
+
+// SetQueryRewriter sets the function rewriting the query before each request
+func (c *SearchTwitterClient) SetQueryRewriter(rewriter func(query string, batch int) string) {
+	c.queryRewriter = rewriter
+}
+
+// Search searches tweets given a search parameter 'q' till either there are no more results or the rate limit is exceeded.
+// Paging by max_id relies on results being ordered by ID, which only holds for the recent result type; for the mixed and popular
+// result types a single page is returned, unless SetPaginatePopular is set for the next_results cursor returned by Twitter to be
+// followed instead, a single page being returned when it is missing.
+// The result types set by SetResultTypeFallback are tried in turn, if any.
+func (c *SearchTwitterClient) Search(query string) (*SearchTweetsResponse, error) {
+
+	c.resetCancel()
+
+	result, err := c.collect(query)
+	if err != nil {
+		return nil, err
+	}
+	c.MaxID = result.lastMaxID
 
 	return result, nil
 }
+
+// collect searches tweets the way Search does, with the fallback chain, the reservoir sample or the spill file, the catch-up and the
+// ordering of SetOldestFirst, leaving the since_id and max_id of the client unchanged
+func (c *SearchTwitterClient) collect(query string) (*SearchTweetsResponse, error) {
+
+	filters := c.newFilterState()
+	result, err := c.searchWithFallback(query, filters)
+	if err != nil {
+		return nil, err
+	}
+	if c.catchUp && c.MaxID == 0 && c.resultType() == "recent" && result.StopReason == StopReasonExhausted &&
+		result.spill == nil && c.reservoirSize <= 0 && result.newestID > 0 {
+		if err = c.catchUpNewer(query, result, filters); err != nil {
+			return nil, err
+		}
+	}
+	if result.requests > 0 {
+		result.RateLimitEfficiency = float64(result.TotalTweets()) / float64(result.requests)
+	}
+
+	if c.oldestFirst && result.spill == nil {
+		reverseTweets(result.Tweets)
+	}
+	result.attachRawTweets()
+
+	return result, nil
+}
+
+// searchWithFallback searches with each result type of the fallback chain in turn till one yields tweets, spilled ones included,
+// the ResultType field being restored afterwards, or with the ResultType field alone when there is no chain. It stops early on a search
+// that did not run out of results, such as a rate limited one, a further result type hitting the same limit. The spill file of an
+// attempt discarded for a further result type is removed, its requests still counting towards those of the result
+func (c *SearchTwitterClient) searchWithFallback(query string, filters *filterState) (*SearchTweetsResponse, error) {
+	if len(c.resultTypeFallback) == 0 {
+		return c.searchOrSample(context.Background(), query, c.SinceID, c.MaxID, filters)
+	}
+
+	defer func(resultType string) {
+		c.ResultType = resultType
+	}(c.ResultType)
+
+	var result *SearchTweetsResponse
+	requests := 0
+	for _, resultType := range c.resultTypeFallback {
+		c.ResultType = resultType
+		attempt, err := c.searchOrSample(context.Background(), query, c.SinceID, c.MaxID, filters)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			result.Close()
+		}
+		result = attempt
+		requests += attempt.requests
+		result.requests = requests
+		if result.TotalTweets() > 0 || result.StopReason != StopReasonExhausted {
+			break
+		}
+		if c.logger != nil {
+			c.logger.Debugf("no tweets for result_type=%s, falling back", c.resultType())
+		}
+	}
+	return result, nil
+}
+
+// reverseTweets reverses the order of the given tweets in place
+func reverseTweets(tweets []twittergo.Tweet) {
+	for i, j := 0, len(tweets)-1; i < j; i, j = i+1, j-1 {
+		tweets[i], tweets[j] = tweets[j], tweets[i]
+	}
+}
+
+// search pages through the tweets between sinceID and maxID, collecting them into the result
+func (c *SearchTwitterClient) search(ctx context.Context, query string, sinceID uint64, maxID uint64, filters *filterState) (*SearchTweetsResponse, error) {
+
+	tweets := []twittergo.Tweet{}
+	var rawTweets map[uint64][]byte
+	result, err := c.paginate(ctx, query, sinceID, maxID, false, filters, func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool {
+		tweets = append(tweets, batch...)
+		rawTweets = keepRawTweets(rawTweets, batch, page.rawTweets)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Tweets = tweets
+	result.rawTweets = rawTweets
+	result.computeIDBounds()
+
+	return result, nil
+}
+
+// paginate pages through the tweets between sinceID and maxID, handing the filtered tweets and the page of each batch to onBatch,
+// which returns false to stop, the filters spanning the whole search sharing the given state. In raw mode tweets are only decoded as far as pagination requires and none is handed to onBatch.
+// The result holds everything but the tweets, along with the max_id of the last request and the newest ID seen.
+// Cancelling the context stops it at the next batch boundary, like Cancel does
+func (c *SearchTwitterClient) paginate(ctx context.Context, query string, sinceID uint64, maxID uint64, raw bool, filters *filterState, onBatch func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool) (*SearchTweetsResponse, error) {
+	return c.paginateFrom(ctx, query, sinceID, maxID, "", raw, filters, onBatch)
+}
+
+// paginateFrom pages through a search like paginate does, the first page being fetched by following the given next_results cursor
+// instead when it is not empty
+func (c *SearchTwitterClient) paginateFrom(ctx context.Context, query string, sinceID uint64, maxID uint64, cursor string, raw bool, filters *filterState, onBatch func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool) (*SearchTweetsResponse, error) {
+
+	if err := c.checkIDRange(sinceID, maxID); err != nil {
+		return nil, err
+	}
+
+	result := &SearchTweetsResponse{
+		lastMaxID: maxID,
+		query:     query,
+	}
+
+	recent := c.resultType() == "recent"
+	nextResults := cursor
+	failures := 0
+	var lastSpan uint64
+	var predictor exhaustionPredictor
+	oldestPageFull := false
+	var lowestID uint64
+
+	var pipeline *batchPipeline
+	if c.onBatch != nil && !raw {
+		pipeline = newBatchPipeline(c.onBatch, c.batchCallbackConcurrency)
+		defer pipeline.wait()
+	}
+
+	for counter := 1; ; counter++ {
+		if pipeline != nil {
+			if err := pipeline.err(); err != nil {
+				return nil, err
+			}
+		}
+		if c.cancelled.Load() || ctx.Err() != nil {
+			result.StopReason = StopReasonCancelled
+			break
+		}
+
+		var response *SearchTweetsResponse
+		var err error
+		if (counter == 1 && len(nextResults) == 0) || recent {
+			response, err = c.searchForMore(ctx, c.rewriteQuery(query, counter), sinceID, result.lastMaxID, raw)
+		} else {
+			response, err = c.searchNextResults(ctx, nextResults, c.rewriteQuery(query, counter), raw)
+		}
+		if err != nil || !response.cached {
+			result.requests++
+			filters.countRequest()
+		}
+		if err != nil {
+			if !c.continueOnError || ctx.Err() != nil {
+				return nil, err
+			}
+			result.Errors = append(result.Errors, err)
+			failures++
+			if c.logger != nil {
+				c.logger.Warnf("batch #%d failed, %d in a row: %v", counter, failures, err)
+			}
+			skipped := recent && lastSpan > 0 && result.lastMaxID > sinceID+lastSpan+1
+			if !skipped || failures >= maxConsecutiveFailures {
+				result.StopReason = StopReasonFailed
+				break
+			}
+			result.lastMaxID -= lastSpan + 1
+			continue
+		}
+		failures = 0
+
+		if c.collectTimings {
+			result.BatchTimings = append(result.BatchTimings, response.latency)
+			result.BatchResultTypes = append(result.BatchResultTypes, response.resultType)
+			if c.requestIDGenerator != nil {
+				result.BatchRequestIDs = append(result.BatchRequestIDs, response.requestID)
+			}
+		}
+		if response.HasRateLimit {
+			result.HasRateLimit = true
+			result.RateLimit = response.RateLimit
+			result.RateLimitRemaining = response.RateLimitRemaining
+			result.RateLimitReset = response.RateLimitReset
+		}
+
+		if c.logger != nil {
+			c.logger.Debugf("response #%d%s got %d tweets, HasRateLimit = %v, RateLimit = %d, RateLimitRemaining = %d, RateLimitReset = %v", counter, requestIDSuffix(response.requestID), len(response.Tweets), response.HasRateLimit, response.RateLimit, response.RateLimitRemaining, response.RateLimitReset)
+		}
+
+		if c.predictExhaustion && c.logger != nil && response.HasRateLimit {
+			if exhaustion, predicted := predictor.observe(c.now(), response.RateLimitRemaining); predicted && exhaustion.Before(response.RateLimitReset) {
+				c.logger.Warnf("rate limit predicted to be exhausted at %v, before its reset at %v", exhaustion, response.RateLimitReset)
+			} else if predicted {
+				c.logger.Debugf("rate limit predicted to last till its reset at %v", response.RateLimitReset)
+			}
+		}
+
+		addCount(c.metrics.Tweets, len(response.Tweets))
+		if len(response.Tweets) > 0 {
+			oldestPageFull = response.requestedCount > 0 && len(response.Tweets) >= response.requestedCount
+		}
+		if newestID := maxTweetID(response.Tweets); newestID > result.newestID {
+			result.newestID = newestID
+		}
+
+		if recent && lowestID > 0 {
+			response.Tweets = dropSeenTweets(response.Tweets, lowestID)
+		}
+
+		reachedStopID := false
+		if c.stopAtID > 0 {
+			response.Tweets, reachedStopID = trimAtID(response.Tweets, c.stopAtID)
+		}
+
+		var batch []twittergo.Tweet
+		stopRequested := false
+		if !raw {
+			batch, stopRequested = c.filterTweets(response.Tweets, filters)
+		}
+		if pipeline != nil {
+			if err := pipeline.run(batch); err != nil {
+				return nil, err
+			}
+		}
+		proceed := onBatch(batch, response)
+		authors, engagement := filters.record(batch)
+
+		collected := len(batch)
+		if raw {
+			collected = len(response.Tweets)
+		}
+		if tweets, requests := filters.progress(collected); c.onProgress != nil {
+			c.onProgress(tweets, requests, result.RateLimitRemaining)
+		}
+
+		minID := minTweetID(response.Tweets)
+		if response.rateLimited {
+			result.StopReason = StopReasonRateLimited
+		} else if !proceed || stopRequested {
+			result.StopReason = StopReasonStopped
+		} else if reachedStopID {
+			result.StopReason = StopReasonReachedStopID
+		} else if c.maxAuthors > 0 && authors >= c.maxAuthors {
+			result.StopReason = StopReasonMaxAuthors
+		} else if c.stopAtEngagement > 0 && engagement >= int64(c.stopAtEngagement) {
+			result.StopReason = StopReasonReachedEngagement
+		} else if minID <= c.maxIDStep() || len(response.Tweets) < c.minBatchSize || (!recent && (!c.paginatePopular || len(response.nextResults) == 0)) {
+			result.StopReason = StopReasonExhausted
+		} else if result.HasRateLimit && result.RateLimitRemaining == 0 && (c.tokens == nil || !c.tokens.available(c.searchPath())) {
+			result.StopReason = StopReasonRateLimited
+		}
+		resumable := result.StopReason == StopReasonStopped || result.StopReason == StopReasonMaxAuthors ||
+			result.StopReason == StopReasonReachedEngagement || (result.StopReason == StopReasonRateLimited && !response.rateLimited)
+		if len(result.StopReason) == 0 || resumable {
+			if recent {
+				result.lastMaxID = minID - c.maxIDStep()
+				lowestID = minID
+				lastSpan = maxTweetID(response.Tweets) - minID
+			} else {
+				nextResults = response.nextResults
+			}
+		}
+		if len(result.StopReason) > 0 {
+			if c.logger != nil {
+				c.logger.Debugf("will stop, %s", result.StopReason)
+			}
+			break
+		}
+	}
+
+	result.collectedAt = time.Now()
+	result.PossibleGap = sinceID > 0 && recent && result.StopReason == StopReasonExhausted && oldestPageFull
+	if result.StopReason != StopReasonExhausted && result.StopReason != StopReasonReachedStopID {
+		result.Cursor = &SearchCursor{
+			Query:       query,
+			ResultType:  c.resultType(),
+			SinceID:     sinceID,
+			MaxID:       result.lastMaxID,
+			NextResults: nextResults,
+		}
+	}
+
+	if pipeline != nil {
+		if err := pipeline.wait(); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// SearchWindow searches a single batch of tweets given a search parameter 'q', with IDs less than or equal to maxID when it is not zero.
+// Along with the batch it returns the max_id to pass to the following call, which is zero once there are no more results, including
+// when the batch would not page past maxID, such as one holding only the tweet at maxID with a pagination overlap of zero
+func (c *SearchTwitterClient) SearchWindow(query string, maxID uint64) (*SearchTweetsResponse, uint64, error) {
+
+	if err := c.checkIDRange(c.SinceID, maxID); err != nil {
+		return nil, 0, err
+	}
+
+	response, err := c.searchForMore(context.Background(), c.rewriteQuery(query, 1), c.SinceID, maxID, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var nextMaxID uint64
+	minID := minTweetID(response.Tweets)
+	if response.rateLimited {
+		response.StopReason = StopReasonRateLimited
+	} else if minID <= c.maxIDStep() || (maxID > 0 && minID-c.maxIDStep() >= maxID) {
+		response.StopReason = StopReasonExhausted
+	} else {
+		nextMaxID = minID - c.maxIDStep()
+	}
+
+	var stopRequested bool
+	response.Tweets, stopRequested = c.filterTweets(response.Tweets, c.newFilterState())
+	if stopRequested && len(response.StopReason) == 0 {
+		response.StopReason = StopReasonStopped
+		nextMaxID = 0
+	}
+	response.attachRawTweets()
+	if c.collectTimings {
+		response.BatchTimings = []time.Duration{response.latency}
+		response.BatchResultTypes = []string{response.resultType}
+		if c.requestIDGenerator != nil {
+			response.BatchRequestIDs = []string{response.requestID}
+		}
+	}
+	response.computeIDBounds()
+	response.query = query
+	response.collectedAt = time.Now()
+
+	return response, nextMaxID, nil
+}
+
+// SearchSeq returns an iterator over the tweets matching a search parameter 'q', fetching batches as the iteration proceeds,
+// till either there are no more results, the rate limit is exceeded or the loop breaks. An error, including the one of a cancelled
+// context, is yielded once with a nil tweet and ends the iteration. Nothing is yielded once the loop breaks, not even the error of
+// a callback set by SetOnBatch still running at that time
+func (c *SearchTwitterClient) SearchSeq(ctx context.Context, query string) iter.Seq2[twittergo.Tweet, error] {
+	return func(yield func(twittergo.Tweet, error) bool) {
+		c.resetCancel()
+		stopped := false
+		result, err := c.paginate(ctx, query, c.SinceID, c.MaxID, false, c.newFilterState(), func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool {
+			for _, tweet := range batch {
+				if !yield(tweet, nil) {
+					stopped = true
+					return false
+				}
+			}
+			return true
+		})
+		if stopped {
+			return
+		}
+		if err == nil && result.StopReason != StopReasonStopped {
+			err = ctx.Err()
+		}
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// SearchRaw pages through the tweets matching a search parameter 'q' like Search does, but hands the undecoded JSON body of each page
+// to onPage instead of collecting tweets, which are only decoded as far as pagination requires; client-side filters do not apply.
+// An error returned by onPage stops the search and is returned. A search stopped by the rate limit returns a twittergo.RateLimitError
+// telling when it resets, and a cancelled one the context error
+func (c *SearchTwitterClient) SearchRaw(ctx context.Context, query string, onPage func(raw []byte) error) error {
+
+	c.resetCancel()
+
+	var pageErr error
+	result, err := c.paginate(ctx, query, c.SinceID, c.MaxID, true, c.newFilterState(), func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool {
+		if len(page.raw) == 0 {
+			return true
+		}
+		pageErr = onPage(page.raw)
+		return pageErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	if pageErr != nil {
+		return pageErr
+	}
+
+	switch result.StopReason {
+	case StopReasonRateLimited:
+		return twittergo.RateLimitError{
+			Limit:     result.RateLimit,
+			Remaining: result.RateLimitRemaining,
+			Reset:     result.RateLimitReset,
+		}
+	case StopReasonCancelled:
+		return ctx.Err()
+	}
+	return nil
+}
+
+// checkIDRange fails with ErrInvalidIDRange when both IDs are set and no tweet can be between them, unless the client is lenient
+func (c *SearchTwitterClient) checkIDRange(sinceID uint64, maxID uint64) error {
+	if sinceID == 0 || maxID == 0 || sinceID < maxID {
+		return nil
+	}
+	if !c.lenientIDRange {
+		return ErrInvalidIDRange
+	}
+	if c.logger != nil {
+		c.logger.Warnf("since_id %d is greater than or equal to max_id %d, no tweet will match", sinceID, maxID)
+	}
+	return nil
+}
+
+// trimAtID returns the tweets newer than the given ID, reporting whether any was not, in place
+func trimAtID(tweets []twittergo.Tweet, stopAtID uint64) ([]twittergo.Tweet, bool) {
+	kept := tweets[:0]
+	for _, tweet := range tweets {
+		if tweetID(tweet) > stopAtID {
+			kept = append(kept, tweet)
+		}
+	}
+	return kept, len(kept) < len(tweets)
+}
+
+// minTweetID returns the smallest ID of the given tweets, or zero if none of them has an ID
+func minTweetID(tweets []twittergo.Tweet) uint64 {
+	var minID uint64
+	for _, tweet := range tweets {
+		if id := tweetID(tweet); id > 0 && (minID == 0 || id < minID) {
+			minID = id
+		}
+	}
+	return minID
+}
+
+// dropSeenTweets returns the tweets with an ID lower than the lowest one seen by the previous pages, dropping those a page
+// overlapping the previous one returned again
+func dropSeenTweets(tweets []twittergo.Tweet, lowestID uint64) []twittergo.Tweet {
+	unseen := make([]twittergo.Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if tweetID(tweet) < lowestID {
+			unseen = append(unseen, tweet)
+		}
+	}
+	return unseen
+}
+
+// maxIDStep returns how much is subtracted from the smallest ID of a page for the max_id of the next one, see SetPaginationOverlap
+func (c *SearchTwitterClient) maxIDStep() uint64 {
+	if c.paginationOverlap == nil {
+		return 1
+	}
+	return *c.paginationOverlap
+}
+
+// tweetID returns the ID of a tweet, or zero if it has none. The exact id_str field is preferred over the numeric id field,
+// since JSON numbers are decoded as float64 which cannot represent IDs above 2^53 exactly
+func tweetID(tweet twittergo.Tweet) uint64 {
+	if idStr, isString := tweet["id_str"].(string); isString {
+		if id, err := strconv.ParseUint(idStr, 10, 64); err == nil {
+			return id
+		}
+	}
+	switch id := tweet["id"].(type) {
+	case json.Number:
+		if parsed, err := strconv.ParseUint(id.String(), 10, 64); err == nil {
+			return parsed
+		}
+	case float64:
+		if id > 0 {
+			return uint64(id)
+		}
+	}
+	return 0
+}
+
+func (c *SearchTwitterClient) searchForMore(ctx context.Context, query string, sinceID uint64, maxID uint64, raw bool) (*SearchTweetsResponse, error) {
+
+	queryParams := url.Values{}
+	queryParams.Set("count", strconv.Itoa(c.batchSize()))
+	queryParams.Set("q", query)
+	if len(c.Language) > 0 && !langOperatorPattern.MatchString(query) {
+		queryParams.Set("lang", c.Language)
+	}
+	if maxID > 0 {
+		queryParams.Set("max_id", strconv.FormatUint(maxID, 10))
+	}
+	queryParams.Set("result_type", c.resultType())
+	if sinceID > 0 {
+		queryParams.Set("since_id", strconv.FormatUint(sinceID, 10))
+	}
+	if c.excludeEntities {
+		queryParams.Set("include_entities", "false")
+	}
+
+	return c.sendSearchRequest(ctx, queryParams, raw)
+}
+
+// EffectiveQuery returns the q parameter the first request of a search for baseQuery sends, as composed by the rewriter set by
+// SetQueryRewriter, if any. The client adds no operator of its own: every other filter of the client is applied client-side after
+// fetching, and the language is sent as the separate lang parameter unless the query has a lang: operator
+func (c *SearchTwitterClient) EffectiveQuery(baseQuery string) string {
+	return c.rewriteQuery(baseQuery, 1)
+}
+
+// rewriteQuery returns the query to send for the given batch, numbered from 1, as returned by the rewriter set by SetQueryRewriter
+func (c *SearchTwitterClient) rewriteQuery(query string, batch int) string {
+	if c.queryRewriter == nil {
+		return query
+	}
+	return c.queryRewriter(query, batch)
+}
+
+// resultType returns the result_type query parameter, defaulting to mixed when the ResultType field was left empty or set to an
+// unsupported value, so that every request of a search sends the same value
+func (c *SearchTwitterClient) resultType() string {
+	if c.ResultType == "recent" || c.ResultType == "popular" {
+		return c.ResultType
+	}
+	return "mixed"
+}
+
+// searchNextResults follows the next_results cursor of a previous page, which already carries every query parameter but q,
+// overridden in case it was rewritten, and include_entities, which Twitter always sets to true
+func (c *SearchTwitterClient) searchNextResults(ctx context.Context, nextResults string, query string, raw bool) (*SearchTweetsResponse, error) {
+
+	queryParams, err := url.ParseQuery(strings.TrimPrefix(nextResults, "?"))
+	if err != nil {
+		return nil, err
+	}
+	queryParams.Set("q", query)
+	if c.excludeEntities {
+		queryParams.Set("include_entities", "false")
+	}
+
+	return c.sendSearchRequest(ctx, queryParams, raw)
+}
+
+// sendSearchRequest sends a search request and decodes the page it returns, keeping its raw body. In raw mode the tweets
+// of the page only hold their id_str, as needed for pagination
+func (c *SearchTwitterClient) sendSearchRequest(ctx context.Context, queryParams url.Values, raw bool) (*SearchTweetsResponse, error) {
+
+	queryURL := fmt.Sprintf("%s?%v", c.searchPath(), encodeQuery(queryParams))
+	requestedCount, _ := strconv.Atoi(queryParams.Get("count"))
+	if c.responseCache != nil {
+		if body, hit := c.responseCache.Get(queryURL); hit {
+			if c.logger != nil {
+				c.logger.Debugf("serving %s from the response cache", queryURL)
+			}
+			result := &SearchTweetsResponse{
+				Tweets:         []twittergo.Tweet{},
+				resultType:     queryParams.Get("result_type"),
+				requestedCount: requestedCount,
+				cached:         true,
+			}
+			return c.parseSearchBody(result, body, raw)
+		}
+	}
+
+	requestID := ""
+	if c.requestIDGenerator != nil {
+		requestID = c.requestIDGenerator()
+		ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	}
+
+	if c.coordinator != nil {
+		if granted, rateLimit, reset := c.coordinator.acquire(c.now()); !granted {
+			addCount(c.metrics.RateLimitHits, 1)
+			if c.logger != nil {
+				c.logger.Debugf("shared rate limit budget exhausted till %v", reset)
+			}
+			return &SearchTweetsResponse{
+				Tweets:         []twittergo.Tweet{},
+				HasRateLimit:   true,
+				RateLimit:      rateLimit,
+				RateLimitReset: reset,
+				resultType:     queryParams.Get("result_type"),
+				rateLimited:    true,
+			}, nil
+		}
+	}
+
+	start := c.now()
+	response, err := c.sendRequest(ctx, queryURL)
+	latency := c.now().Sub(start)
+	if c.autoTune {
+		c.tuneBatchSize(latency, err != nil || response.StatusCode >= http.StatusInternalServerError)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchTweetsResponse{
+		Tweets:         []twittergo.Tweet{},
+		latency:        latency,
+		resultType:     queryParams.Get("result_type"),
+		requestedCount: requestedCount,
+		requestID:      requestID,
+	}
+
+	if response.HasRateLimit() {
+		result.setRateLimit(response)
+		c.recordRateLimit(result)
+	}
+
+	var timedOut atomic.Bool
+	if c.parseTimeout > 0 {
+		timer := time.AfterFunc(c.parseTimeout, func() {
+			timedOut.Store(true)
+			response.Body.Close()
+		})
+		defer timer.Stop()
+	}
+
+	body, err := readBody(response)
+	if timedOut.Load() {
+		return nil, ErrParseTimeout
+	}
+	if err != nil {
+		if rateLimitErr, isRateLimitErr := err.(twittergo.RateLimitError); isRateLimitErr {
+			result.setRateLimit(rateLimitErr)
+			result.rateLimited = true
+			c.recordRateLimit(result)
+			addCount(c.metrics.RateLimitHits, 1)
+			return result, nil
+		}
+		return nil, err
+	}
+	if c.responseCache != nil && response.StatusCode == http.StatusOK && len(body) > 0 {
+		c.responseCache.Set(queryURL, body, c.cacheTTL())
+	}
+
+	result, err = c.parseSearchBody(result, body, raw)
+	if timedOut.Load() {
+		return nil, ErrParseTimeout
+	}
+	return result, err
+}
+
+// parseSearchBody parses the body of a search response into the tweets and the cursor of the result
+func (c *SearchTwitterClient) parseSearchBody(result *SearchTweetsResponse, body []byte, raw bool) (*SearchTweetsResponse, error) {
+	result.raw = body
+
+	if len(body) == 0 {
+		return result, nil
+	}
+
+	var parser ResponseParser = StandardResponseParser{}
+	if c.responseParser != nil {
+		parser = c.responseParser
+	}
+	statuses, nextResults, err := parser.ParseSearch(body)
+	if err != nil {
+		return nil, err
+	}
+	if c.preserveRawJSON && !raw {
+		result.rawTweets = make(map[uint64][]byte, len(statuses))
+	}
+	for _, status := range statuses {
+		if tweet, err := decodeTweet(status, raw); err != nil {
+			if c.onMalformedTweet != nil {
+				c.onMalformedTweet(status, err)
+			}
+		} else {
+			result.Tweets = append(result.Tweets, tweet)
+			if result.rawTweets != nil {
+				result.rawTweets[tweetID(tweet)] = append([]byte(nil), status...)
+			}
+		}
+	}
+	result.nextResults = nextResults
+
+	return result, nil
+}
+
+// decodeTweet decodes a tweet, failing with ErrMalformedTweet when it is not an object with an ID. In raw mode only its ID is decoded
+func decodeTweet(status json.RawMessage, raw bool) (twittergo.Tweet, error) {
+	if raw {
+		var ids struct {
+			IDStr string      `json:"id_str"`
+			ID    json.Number `json:"id"`
+		}
+		if err := json.Unmarshal(status, &ids); err != nil {
+			return nil, err
+		}
+		id := tweetID(twittergo.Tweet{"id_str": ids.IDStr, "id": ids.ID})
+		if id == 0 {
+			return nil, ErrMalformedTweet
+		}
+		return twittergo.Tweet{"id_str": strconv.FormatUint(id, 10)}, nil
+	}
+
+	tweet := twittergo.Tweet{}
+	if err := json.Unmarshal(status, &tweet); err != nil {
+		return nil, err
+	}
+	if tweet == nil || tweetID(tweet) == 0 {
+		return nil, ErrMalformedTweet
+	}
+	return tweet, nil
+}
+
+// readBody returns the body of a successful response or, for any other status, the error parsed by twittergo,
+// such as a twittergo.RateLimitError. A response without content has an empty body
+func readBody(response *twittergo.APIResponse) ([]byte, error) {
+	if response.StatusCode != http.StatusOK {
+		var ignored interface{}
+		return nil, response.Parse(&ignored)
+	}
+
+	defer response.Body.Close()
+	var reader io.Reader = response.Body
+	if strings.Contains(strings.ToLower(response.Header.Get("Content-Encoding")), "gzip") {
+		gzipReader, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		reader = gzipReader
+	}
+	return io.ReadAll(reader)
+}
+
+// batchSize returns the number of tweets to request per batch
+func (c *SearchTwitterClient) batchSize() int {
+	if c.autoTune {
+		return int(c.tunedBatchSize.Load())
+	}
+	return BatchSize
+}
+
+// tuneBatchSize adjusts the auto-tuned batch size given the latency and outcome of the last request
+func (c *SearchTwitterClient) tuneBatchSize(latency time.Duration, failed bool) {
+	size := c.tunedBatchSize.Load()
+	switch {
+	case failed || latency > autoTuneSlowLatency:
+		size /= 2
+		if size < autoTuneMinBatchSize {
+			size = autoTuneMinBatchSize
+		}
+	case latency < autoTuneFastLatency:
+		size *= 2
+		if size > BatchSize {
+			size = BatchSize
+		}
+	}
+	c.tunedBatchSize.Store(size)
+	if c.logger != nil {
+		c.logger.Debugf("batch size tuned to %d after a %v response", size, latency)
+	}
+}
+
+// encodeQuery encodes query parameters sorted by key and percent-encoded as per RFC 3986, the way OAuth signing encodes them.
+// Unlike url.Values.Encode, spaces are encoded as %20 rather than +, and any UTF-8 text such as emoji, CJK or RTL scripts
+// is encoded byte by byte, so the q parameter reaches Twitter identically whichever the authentication
+func encodeQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(values))
+	for _, key := range keys {
+		for _, value := range values[key] {
+			pairs = append(pairs, oauth1a.Rfc3986Escape(key)+"="+oauth1a.Rfc3986Escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// sendRequest sends a GET request, retrying network errors and 5xx statuses as configured by SetMaxRetries
+// When rotating across several tokens, a rate limited request is sent again right away using the next available token.
+// With user auth, a request rejected because of clock skew is signed again and sent once more, see syncClock
+func (c *SearchTwitterClient) sendRequest(ctx context.Context, queryURL string) (*twittergo.APIResponse, error) {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	skewRetried := false
+	rotations := 0
+	for attempt := 1; ; {
+		request, err := c.newRequest(ctx, queryURL)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := c.sendOnce(request)
+		if err == nil && c.tokens != nil && response.StatusCode == twittergo.STATUS_LIMIT &&
+			rotations < len(c.tokens.tokens) && c.tokens.available(request.URL.Path) {
+			rotations++
+			response.Body.Close()
+			if c.logger != nil {
+				c.logger.Debug("token rate limited, rotating to the next one")
+			}
+			continue
+		}
+		if err == nil && c.tokens == nil && c.signer == nil && !skewRetried && c.TwitterClient.User != nil && c.syncClock(response) {
+			skewRetried = true
+			response.Body.Close()
+			continue
+		}
+		if err == nil && response.StatusCode < http.StatusInternalServerError {
+			c.limitBody(response)
+			return response, nil
+		}
+
+		if attempt > c.maxRetries {
+			if err != nil {
+				return nil, err
+			}
+			c.limitBody(response)
+			return response, nil
+		}
+
+		if err == nil {
+			response.Body.Close()
+		}
+
+		backoff := c.backoff
+		if backoff == nil {
+			backoff = defaultBackoff
+		}
+		delay := backoff(attempt)
+		if c.logger != nil {
+			c.logger.Debugf("request%s failed, retry #%d in %v", requestIDSuffix(requestID), attempt, delay)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.after(delay):
+		}
+		addCount(c.metrics.Retries, 1)
+		attempt++
+	}
+}
+
+// newRequest builds a GET request carrying the extra headers set by SetRequestHeaders, the request ID of the context, if any,
+// and the timestamp correcting the clock skew, if one was detected
+func (c *SearchTwitterClient) newRequest(ctx context.Context, queryURL string) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", queryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.requestHeaders {
+		request.Header[name] = append([]string(nil), values...)
+	}
+	if requestID, _ := ctx.Value(requestIDKey{}).(string); len(requestID) > 0 {
+		request.Header.Set(requestIDHeader, requestID)
+	}
+	if skew := c.clockSkew.Load(); skew != 0 {
+		request.Header.Set("X-OAuth-Timestamp", strconv.FormatInt(time.Now().Add(time.Duration(skew)).Unix(), 10))
+	}
+	return request, nil
+}
+
+// sendOnce sends a request a single time, without any retry, using the next available token when rotating across several of them,
+// or else the signer given to NewClientWithSigner, if any, or else the credentials of the client
+func (c *SearchTwitterClient) sendOnce(request *http.Request) (*twittergo.APIResponse, error) {
+	addCount(c.metrics.Requests, 1)
+	if c.tokens != nil {
+		endpoint := request.URL.Path
+		token := c.tokens.acquire(endpoint)
+		response, err := token.client.SendRequest(request)
+		if err == nil {
+			c.tokens.observe(token, endpoint, response)
+		}
+		return response, err
+	}
+	if c.signer != nil {
+		return c.sendSigned(request)
+	}
+	return c.TwitterClient.SendRequest(request)
+}
+
+// requestIDKey is the context key of the ID of a search request, generated by the function set by SetRequestIDGenerator
+type requestIDKey struct{}
+
+// requestIDSuffix returns the request ID to append to a log line, an empty string when there is none
+func requestIDSuffix(requestID string) string {
+	if len(requestID) == 0 {
+		return ""
+	}
+	return " [" + requestID + "]"
+}
+
+// syncClock detects a user auth request rejected with the "Timestamp out of bounds" error, code 135, which OAuth signatures get
+// when the local clock is skewed from the one of Twitter. It then records the skew measured against the Date header of the response,
+// the timestamp of the OAuth signature of every later request being corrected by it, and reports whether it did. Any other response
+// is left readable as received
+func (c *SearchTwitterClient) syncClock(response *twittergo.APIResponse) bool {
+	if response.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	serverTime, err := http.ParseTime(response.Header.Get("Date"))
+	if err != nil {
+		return false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxErrorBodyBytes))
+	response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	apiErrors := struct {
+		Errors []struct {
+			Code int `json:"code"`
+		} `json:"errors"`
+	}{}
+	if json.Unmarshal(body, &apiErrors) != nil {
+		return false
+	}
+	for _, apiError := range apiErrors.Errors {
+		if apiError.Code == timestampOutOfBoundsCode {
+			skew := time.Until(serverTime)
+			c.clockSkew.Store(int64(skew))
+			if c.logger != nil {
+				c.logger.Warnf("OAuth timestamp out of bounds, correcting the clock skew of %v and retrying", skew)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// defaultBackoff doubles the delay with each attempt, starting from one second and capped at one minute
+func defaultBackoff(attempt int) time.Duration {
+	if attempt > 6 {
+		return time.Minute
+	}
+	return time.Second << uint(attempt-1)
+}
+
+// limitBody wraps the body of a response so that reading more than the size set by SetMaxResponseBytes fails
+func (c *SearchTwitterClient) limitBody(response *twittergo.APIResponse) {
+	if c.maxResponseBytes > 0 {
+		response.Body = &limitedBody{
+			ReadCloser: response.Body,
+			reader:     io.LimitReader(response.Body, c.maxResponseBytes+1),
+			remaining:  c.maxResponseBytes,
+		}
+	}
+}
+
+// limitedBody wraps a response body, failing with ErrResponseTooLarge once more than the allowed number of bytes is read
+type limitedBody struct {
+	io.ReadCloser
+	reader    io.Reader
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	return n, err
+}
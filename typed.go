@@ -0,0 +1,119 @@
+package twitterquerygo
+
+import (
+	"time"
+
+	"github.com/kurrik/twittergo"
+)
+
+// Tweet is a strongly-typed view of the fields of a twittergo.Tweet most searches need, as converted by ToTweet.
+// A field missing from the tweet keeps its zero value
+type Tweet struct {
+	ID            uint64
+	Text          string
+	CreatedAt     time.Time
+	Author        User
+	RetweetCount  int64
+	FavoriteCount int64
+	Lang          string
+	Entities      Entities
+}
+
+// User is a strongly-typed view of the author of a tweet, its zero value standing for an unknown author, such as a deleted
+// or suspended account
+type User struct {
+	ID         uint64
+	ScreenName string
+	Name       string
+	CreatedAt  time.Time
+	Protected  bool
+	Verified   bool
+}
+
+// Entities holds the entities of a tweet, empty when the tweet carries none, such as when they were not requested
+type Entities struct {
+	// Hashtags holds the text of the hashtags, without the # and with their case kept
+	Hashtags []string
+	// Mentions holds the screen names of the mentioned users, without the @
+	Mentions []string
+	// URLs holds the expanded form of the t.co links, as returned by ExpandedURLs
+	URLs []string
+	// Media holds the HTTPS URLs of the attached photos and video thumbnails
+	Media []string
+}
+
+// ToTweet converts a twittergo.Tweet to a Tweet. Unlike the accessors of twittergo.Tweet it never panics, a missing or malformed
+// field being left to its zero value. The text is the one returned by TweetText, the language the one detected by Twitter and
+// the timestamps are in UTC
+func ToTweet(t twittergo.Tweet) Tweet {
+	return toTweetIn(t, time.UTC)
+}
+
+// toTweetIn converts a twittergo.Tweet to a Tweet like ToTweet does, with its timestamps in the given location
+func toTweetIn(t twittergo.Tweet, loc *time.Location) Tweet {
+	author := tweetUser(t)
+	name, _ := author["name"].(string)
+	protected, _ := author["protected"].(bool)
+	verified, _ := author["verified"].(bool)
+
+	return Tweet{
+		ID:        tweetID(t),
+		Text:      TweetText(t),
+		CreatedAt: inLocation(tweetCreatedAt(t), loc),
+		Author: User{
+			ID:         tweetUserID(t),
+			ScreenName: tweetScreenName(t),
+			Name:       name,
+			CreatedAt:  inLocation(tweetUserCreatedAt(t), loc),
+			Protected:  protected,
+			Verified:   verified,
+		},
+		RetweetCount:  tweetCount(t, "retweet_count"),
+		FavoriteCount: tweetCount(t, "favorite_count"),
+		Lang:          tweetLanguage(t),
+		Entities: Entities{
+			Hashtags: entityStrings(t, "hashtags", "text"),
+			Mentions: entityStrings(t, "user_mentions", "screen_name"),
+			URLs:     ExpandedURLs(t),
+			Media:    entityStrings(t, "media", "media_url_https"),
+		},
+	}
+}
+
+// inLocation returns the given time in the given location, the zero time being left as is
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(loc)
+}
+
+// entityStrings returns the non-empty string values of the given field of the entities of the given type of a tweet
+func entityStrings(t twittergo.Tweet, entityType string, field string) []string {
+	var values []string
+	for _, entity := range tweetEntities(t, entityType) {
+		if value, isString := entity[field].(string); isString && len(value) > 0 {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// SearchTyped searches tweets given a search parameter 'q' like Search does, returning them converted by ToTweet along with
+// the response, every tweet of which is converted, those spilled to disk included. The timestamps are in the location set by
+// SetDisplayLocation
+func (c *SearchTwitterClient) SearchTyped(query string) ([]Tweet, *SearchTweetsResponse, error) {
+	result, err := c.Search(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tweets := make([]Tweet, 0, result.TotalTweets())
+	for tweet, err := range result.AllTweets() {
+		if err != nil {
+			return nil, result, err
+		}
+		tweets = append(tweets, toTweetIn(tweet, c.location()))
+	}
+	return tweets, result, nil
+}
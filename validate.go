@@ -0,0 +1,86 @@
+package twitterquerygo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kurrik/twittergo"
+)
+
+// maxQueryLength is the maximum length in characters of the q parameter of the standard search endpoint, operators included
+const maxQueryLength = 500
+
+// ErrEmptyQuery is returned by Validate for a query holding nothing but whitespace
+var ErrEmptyQuery = errors.New("twitterquerygo: empty query")
+
+// ErrQueryTooLong is returned by Validate for a query longer than the search endpoint accepts
+var ErrQueryTooLong = fmt.Errorf("twitterquerygo: query longer than %d characters", maxQueryLength)
+
+// ErrUnbalancedQuotes is returned by Validate for a query with an odd number of double quotes
+var ErrUnbalancedQuotes = errors.New("twitterquerygo: unbalanced quotes in query")
+
+// ErrUnbalancedParentheses is returned by Validate for a query whose parentheses outside of quotes do not match
+var ErrUnbalancedParentheses = errors.New("twitterquerygo: unbalanced parentheses in query")
+
+// Validate checks a search configuration without collecting anything, for pipelines to fail fast on a bad one: the query, as rewritten
+// for its first batch, is checked for obvious syntax errors, reported as ErrEmptyQuery, ErrQueryTooLong, ErrUnbalancedQuotes or
+// ErrUnbalancedParentheses, and the credentials are checked by a single request for the rate limit status of the search endpoint,
+// which does not draw from the search budget, sent once without any retry or token rotation. Every problem found is returned, joined by errors.Join, nil meaning none
+func (c *SearchTwitterClient) Validate(query string) error {
+	errs := validateQuery(c.EffectiveQuery(query))
+
+	request, err := c.newRequest(context.Background(), "/1.1/application/rate_limit_status.json?resources=search")
+	if err == nil {
+		var response *twittergo.APIResponse
+		if response, err = c.sendOnce(request); err == nil {
+			c.limitBody(response)
+			var ignored interface{}
+			err = response.Parse(&ignored)
+		}
+	}
+	if err != nil {
+		errs = append(errs, fmt.Errorf("twitterquerygo: checking the credentials: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateQuery returns the syntax errors found in a query
+func validateQuery(query string) []error {
+	if len(strings.TrimSpace(query)) == 0 {
+		return []error{ErrEmptyQuery}
+	}
+
+	var errs []error
+	if utf8.RuneCountInString(query) > maxQueryLength {
+		errs = append(errs, ErrQueryTooLong)
+	}
+
+	quoted := false
+	unbalanced := false
+	depth := 0
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '(' && !quoted:
+			depth++
+		case r == ')' && !quoted:
+			depth--
+			if depth < 0 {
+				unbalanced = true
+				depth = 0
+			}
+		}
+	}
+	if quoted {
+		errs = append(errs, ErrUnbalancedQuotes)
+	}
+	if unbalanced || depth != 0 {
+		errs = append(errs, ErrUnbalancedParentheses)
+	}
+	return errs
+}
@@ -0,0 +1,173 @@
+package twitterquerygo
+
+import (
+	"container/list"
+	"context"
+	"time"
+
+	"github.com/kurrik/twittergo"
+)
+
+// Watch polls for tweets newer than the last one seen, waiting the given interval between polls, invoking onTweet for each of them from
+// oldest to newest, till the context is cancelled, in which case the context error is returned, or till Cancel is called, in which case
+// nil is returned once the tweets of the interrupted poll are delivered, or ErrCancelled at the next tick when paused. Polling starts
+// from the since_id saved for the query by the StateStore, if any, or else from the since_id of the client, and advances it with each
+// poll, saving it to the StateStore. Without any since_id, the first poll only takes the newest page rather than the whole backlog.
+// When the rate limit is exceeded, polling waits for it to reset; tweets left uncollected by the interrupted poll are skipped.
+// The first poll happens right away, unless delayed with SetWatchImmediate. Polling can be halted and resumed with Pause and Resume,
+// and slowed down during quiet periods with SetWatchBackoff, whose minimum interval then replaces the given one. An interval of zero
+// or less fails with ErrInvalidWatchInterval. Polls always use the recent result type, the only one paging by ID
+func (c *SearchTwitterClient) Watch(ctx context.Context, query string, interval time.Duration, onTweet func(twittergo.Tweet)) error {
+
+	c.resetCancel()
+	defer c.useRecent()()
+
+	if c.watchBackoffMin > 0 {
+		interval = c.watchBackoffMin
+	}
+	if interval <= 0 {
+		return ErrInvalidWatchInterval
+	}
+	sinceID, err := c.loadSinceID(query)
+	if err != nil {
+		return err
+	}
+	seen := newRecentIDs(c.watchDedupWindow)
+
+	for immediate := !c.watchDelayed; ; immediate = false {
+		if !immediate {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-c.after(interval):
+			}
+		}
+
+		if c.paused.Load() {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if c.cancelled.Load() {
+				return ErrCancelled
+			}
+			continue
+		}
+
+		var result *SearchTweetsResponse
+		if sinceID == 0 {
+			result, err = c.searchNewestPage(ctx, query)
+		} else {
+			result, err = c.search(ctx, query, sinceID, 0, c.newFilterState())
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		delivered := 0
+		for i := len(result.Tweets) - 1; i >= 0; i-- {
+			if !seen.add(tweetID(result.Tweets[i])) {
+				onTweet(result.Tweets[i])
+				delivered++
+			}
+		}
+		if result.newestID > sinceID {
+			sinceID = result.newestID
+			if err = c.saveSinceID(query, sinceID); err != nil {
+				return err
+			}
+		}
+
+		if c.logger != nil {
+			c.logger.Debugf("watch got %d new tweets, since_id = %d", len(result.Tweets), sinceID)
+		}
+
+		if result.StopReason == StopReasonCancelled && ctx.Err() == nil {
+			return nil
+		}
+
+		if next := c.nextWatchInterval(interval, delivered); next != interval {
+			interval = next
+			if c.logger != nil {
+				c.logger.Debugf("watch interval now %v", interval)
+			}
+		}
+
+		if result.StopReason == StopReasonRateLimited {
+			if wait := time.Until(result.RateLimitReset); wait > 0 {
+				if c.logger != nil {
+					c.logger.Debugf("watch rate limited, waiting %v", wait)
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-c.after(wait):
+				}
+			}
+		}
+	}
+}
+
+// searchNewestPage searches the single page of the newest tweets a Watch starting without a since_id takes to set it
+func (c *SearchTwitterClient) searchNewestPage(ctx context.Context, query string) (*SearchTweetsResponse, error) {
+	tweets := []twittergo.Tweet{}
+	result, err := c.paginate(ctx, query, 0, 0, false, c.newFilterState(), func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool {
+		tweets = append(tweets, batch...)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Tweets = tweets
+	result.computeIDBounds()
+
+	return result, nil
+}
+
+// nextWatchInterval returns the poll interval following a poll that delivered the given number of tweets: with a watch backoff set,
+// the interval doubles after a poll without new tweets, up to the maximum, and is reset to the minimum once tweets arrive
+func (c *SearchTwitterClient) nextWatchInterval(interval time.Duration, delivered int) time.Duration {
+	if c.watchBackoffMin <= 0 {
+		return interval
+	}
+	if delivered > 0 {
+		return c.watchBackoffMin
+	}
+	return min(2*interval, max(c.watchBackoffMax, c.watchBackoffMin))
+}
+
+// recentIDs is a bounded set of the most recently added IDs, evicting the least recently added one when full
+type recentIDs struct {
+	capacity int
+	order    *list.List
+	elements map[uint64]*list.Element
+}
+
+func newRecentIDs(capacity int) *recentIDs {
+	return &recentIDs{
+		capacity: capacity,
+		order:    list.New(),
+		elements: make(map[uint64]*list.Element),
+	}
+}
+
+// add records an ID and reports whether it was already in the set; a set without capacity records nothing
+func (s *recentIDs) add(id uint64) bool {
+	if s.capacity <= 0 {
+		return false
+	}
+	if element, isPresent := s.elements[id]; isPresent {
+		s.order.MoveToFront(element)
+		return true
+	}
+	s.elements[id] = s.order.PushFront(id)
+	if s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.elements, oldest.Value.(uint64))
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package twitterquerygo
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/kurrik/twittergo"
+)
+
+// weightedQuery holds the scheduling state of a query of SearchWeighted, paginated by its own goroutine one request per turn
+type weightedQuery struct {
+	query   string
+	weight  int
+	current int
+	turn    chan bool
+	events  chan bool
+	result  *SearchTweetsResponse
+	err     error
+}
+
+// SearchWeighted searches tweets for several queries sharing the rate limit, interleaving their requests so that within any window
+// each query gets a number of requests proportional to its weight, a weight less than 1 counting as 1. A query stops on its own once
+// it runs out of results, its share going to the others. Once the rate limit is exceeded every query stops, the ones that did not
+// run out of results getting the StopReasonRateLimited stop reason. The first error stops every query and is returned. The since_id
+// and max_id of the client are used for every query and left unchanged, each of them being paged with the recent result type
+func (c *SearchTwitterClient) SearchWeighted(queries map[string]int) (map[string]*SearchTweetsResponse, error) {
+
+	c.resetCancel()
+	defer c.useRecent()()
+
+	names := make([]string, 0, len(queries))
+	for query := range queries {
+		names = append(names, query)
+	}
+	sort.Strings(names)
+
+	active := make([]*weightedQuery, 0, len(names))
+	for _, query := range names {
+		q := &weightedQuery{
+			query:  query,
+			weight: max(queries[query], 1),
+			turn:   make(chan bool),
+			events: make(chan bool),
+		}
+		active = append(active, q)
+		go c.runWeightedQuery(q)
+	}
+	all := append([]*weightedQuery(nil), active...)
+
+	var stopping *weightedQuery
+	for len(active) > 0 {
+		q := nextWeightedQuery(active)
+		q.turn <- true
+		if finished := <-q.events; !finished {
+			continue
+		}
+
+		active = removeWeightedQuery(active, q)
+		if q.err != nil || q.result.StopReason == StopReasonRateLimited {
+			stopping = q
+			for _, other := range active {
+				other.turn <- false
+				<-other.events
+			}
+			break
+		}
+	}
+
+	results := make(map[string]*SearchTweetsResponse, len(all))
+	for _, q := range all {
+		if q.err != nil {
+			return nil, q.err
+		}
+		if q.result == nil {
+			q.result = &SearchTweetsResponse{Tweets: []twittergo.Tweet{}, query: q.query, collectedAt: time.Now()}
+		}
+		if stopping != nil && q != stopping && q.result.StopReason != StopReasonExhausted {
+			q.result.StopReason = StopReasonRateLimited
+			q.result.HasRateLimit = stopping.result.HasRateLimit
+			q.result.RateLimit = stopping.result.RateLimit
+			q.result.RateLimitRemaining = stopping.result.RateLimitRemaining
+			q.result.RateLimitReset = stopping.result.RateLimitReset
+		}
+		results[q.query] = q.result
+	}
+	return results, nil
+}
+
+// runWeightedQuery paginates a query of SearchWeighted, sending one request per turn granted and reporting after each one whether
+// the query is finished. A turn denied before the first request leaves the result nil
+func (c *SearchTwitterClient) runWeightedQuery(q *weightedQuery) {
+	if !<-q.turn {
+		q.events <- true
+		return
+	}
+
+	tweets := []twittergo.Tweet{}
+	result, err := c.paginate(context.Background(), q.query, c.SinceID, c.MaxID, false, c.newFilterState(), func(batch []twittergo.Tweet, page *SearchTweetsResponse) bool {
+		tweets = append(tweets, batch...)
+		q.events <- false
+		return <-q.turn
+	})
+	if err == nil {
+		result.Tweets = tweets
+		result.computeIDBounds()
+	}
+	q.result, q.err = result, err
+	q.events <- true
+}
+
+// nextWeightedQuery picks the query whose turn it is by smooth weighted round-robin, which spreads the turns of each query evenly
+func nextWeightedQuery(active []*weightedQuery) *weightedQuery {
+	var best *weightedQuery
+	total := 0
+	for _, q := range active {
+		q.current += q.weight
+		total += q.weight
+		if best == nil || q.current > best.current {
+			best = q
+		}
+	}
+	best.current -= total
+	return best
+}
+
+// removeWeightedQuery removes a query from the active ones
+func removeWeightedQuery(active []*weightedQuery, q *weightedQuery) []*weightedQuery {
+	for i, other := range active {
+		if other == q {
+			return append(active[:i], active[i+1:]...)
+		}
+	}
+	return active
+}